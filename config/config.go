@@ -9,3 +9,80 @@ const (
 
 	GasFactor = 1.8
 )
+
+// HD 钱包派生路径，模板中的 %d 为账户序号
+const (
+	DefaultDerivationPath    = "m/44'/60'/0'/0/0"
+	DerivationPathBip44      = "m/44'/60'/0'/0/%d" // metamask、trezor 等默认的布局
+	DerivationPathLedgerLive = "m/44'/60'/%d'/0/0" // ledger live
+	DerivationPathLegacyMEW  = "m/44'/60'/0'/%d"   // 旧版 MEW、ledger legacy
+)
+
+// eip1559 费用估算，基于 eth_feeHistory
+const (
+	FeeHistoryBlocks            = 20 // 读取最近多少个块的费用记录
+	FeeRewardPercentileSlow     = 10 // 小费取每个块交易小费的百分位
+	FeeRewardPercentileStandard = 50
+	FeeRewardPercentileFast     = 90
+	FeeBaseFeeBlocksSlow        = 1 // maxFeePerGas 可承受 base fee 连续上涨(每块最多 12.5%)的块数
+	FeeBaseFeeBlocksStandard    = 3
+	FeeBaseFeeBlocksFast        = 6
+	FeeLegacyFastPercent        = 120 // 不支持 eip1559 的链，fast 的 gas price 为建议值的百分比
+)
+
+// 交易确认等待
+const (
+	TxPollInterval = 3  // 轮询间隔，秒
+	TxDroppedPolls = 20 // 连续多少次查不到交易，视为交易已被节点丢弃
+)
+
+// 本地 nonce 管理
+const (
+	NonceResyncInterval = 60 // 距上次同步超过该时间(秒)，取 nonce 前先与节点的 pending nonce 对账，补上丢失的 nonce
+)
+
+// 替换交易(加速、取消)
+const (
+	TxReplaceMinBumpPercent = 10 // 节点要求替换交易的费用至少上涨的百分比(geth 默认 10%)
+)
+
+// 多节点 rpc 池
+const (
+	RpcHealthCheckInterval = 15  // 健康检查间隔，秒
+	RpcMaxBlockLag         = 5   // 落后最高块超过多少个块视为不健康
+	RpcErrorWindow         = 10  // 错误率统计最近多少次检查
+	RpcMaxErrorRate        = 0.5 // 错误率超过该值视为不健康
+)
+
+// 连接缓存
+const (
+	ChainIdleTimeout   = 600 // 连接闲置超过该时间(秒)被关闭并移出缓存，再次使用时自动重连
+	ChainCheckInterval = 30  // 检查闲置和断线的间隔，秒
+)
+
+// json rpc 批量请求
+const (
+	RpcBatchSize = 100 // 每个批量请求最多包含的调用数，超出后分批发送
+)
+
+// multicall3
+const (
+	Multicall3Address  = "0xcA11bde05977b3631167028862bE2a173976CA11" // 各链统一的部署地址
+	MulticallBatchSize = 500                                          // 一次 aggregate3 最多打包的调用数
+)
+
+// 日志查询 eth_getLogs
+const (
+	LogsBlockRange = 2000 // 每次查询的最大块数，节点返回结果过多或范围超限时自动二分
+)
+
+// 区块扫描
+const (
+	ScanReorgDepth = 64 // 记录最近多少个块的hash，用于回滚时查找分叉点，分叉超过该深度则报错
+)
+
+// 区块交易读取
+const (
+	BlockRangeConcurrency      = 4   // 按范围读取多个块的交易时，同时读取的块数
+	BlockReceiptsRetryInterval = 600 // 节点不支持eth_getBlockReceipts时，改用逐笔查询回执，超过该时间后再尝试，秒
+)
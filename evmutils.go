@@ -1,18 +1,31 @@
 package evmutils
 
 import (
+	"context"
 	"errors"
 	"github.com/bitxx/evm-utils/model"
+	"github.com/bitxx/evm-utils/model/contract"
 	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/model/types"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/bitxx/evm-utils/util/abiutil"
 	"github.com/bitxx/evm-utils/util/signutil"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	eTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/shopspring/decimal"
+	"math/big"
 )
 
 type EvmClient struct {
-	RpcUrl  string
-	timeout int64
+	RpcUrl      string
+	RpcUrls     []string // many rpc urls of the same chain, used instead of RpcUrl if not empty
+	timeout     int64
+	poolOptions model.RpcPoolOptions
 }
 
 // NewEthClient
@@ -28,6 +41,25 @@ func NewEthClient(rpcUrl string, timeout int64) *EvmClient {
 	}
 }
 
+// NewEthClientWithPool
+//
+//	@Description: connect many rpc urls of the same chain, the calls go to the healthy ones
+//	@param rpcUrls all urls must have the same chain id
+//	@param timeout
+//	@param options health check and routing, the zero value uses the defaults in config
+//	@return *EvmClient
+func NewEthClientWithPool(rpcUrls []string, timeout int64, options model.RpcPoolOptions) *EvmClient {
+	client := &EvmClient{
+		RpcUrls:     rpcUrls,
+		timeout:     timeout,
+		poolOptions: options,
+	}
+	if len(rpcUrls) > 0 {
+		client.RpcUrl = rpcUrls[0]
+	}
+	return client
+}
+
 // NewSimpleEthClient
 //
 //	@Description: not support connect to the node
@@ -40,6 +72,29 @@ func (o *EvmClient) AccountByMnemonic() (account *model.Account, err error) {
 	return model.NewAccount().AccountByMnemonic()
 }
 
+// AccountByMnemonicWithOptions
+//
+//	@Description: generate a new mnemonic account
+//	@receiver o
+//	@param wordCount 12, 15, 18, 21 or 24
+//	@param language the bip39 wordlist, eg: model.MnemonicEnglish, model.MnemonicChineseSimplified
+//	@return account
+//	@return err
+func (o *EvmClient) AccountByMnemonicWithOptions(wordCount int, language model.MnemonicLanguage) (account *model.Account, err error) {
+	return model.NewAccount().AccountByMnemonicWithOptions(wordCount, language)
+}
+
+// ValidateMnemonic
+//
+//	@Description: check the words and the checksum of a mnemonic
+//	@receiver o
+//	@param mnemonic
+//	@param language if it is empty, the language will be detected by the words
+//	@return error *model.MnemonicError
+func (o *EvmClient) ValidateMnemonic(mnemonic string, language model.MnemonicLanguage) error {
+	return model.ValidateMnemonic(mnemonic, language)
+}
+
 // AccountInfoByMnemonic
 //
 //	@Description:
@@ -51,6 +106,34 @@ func (o *EvmClient) AccountInfoByMnemonic(mnemonic string) (account *model.Accou
 	return model.NewAccount().AccountInfoByMnemonic(mnemonic)
 }
 
+// AccountInfoByMnemonicPath
+//
+//	@Description: get mnemonic account info with bip39 passphrase and derivation path
+//	@receiver o
+//	@param mnemonic
+//	@param passphrase
+//	@param path eg: m/44'/60'/0'/0/0
+//	@return account
+//	@return err
+func (o *EvmClient) AccountInfoByMnemonicPath(mnemonic, passphrase, path string) (account *model.Account, err error) {
+	return model.NewAccount().AccountInfoByMnemonicPath(mnemonic, passphrase, path)
+}
+
+// DeriveAccounts
+//
+//	@Description: derive many accounts from one mnemonic
+//	@receiver o
+//	@param mnemonic
+//	@param passphrase
+//	@param pathTemplate eg: config.DerivationPathBip44, config.DerivationPathLedgerLive, config.DerivationPathLegacyMEW
+//	@param from the first index
+//	@param count
+//	@return []model.Account
+//	@return error
+func (o *EvmClient) DeriveAccounts(mnemonic, passphrase, pathTemplate string, from, count uint32) ([]model.Account, error) {
+	return model.NewAccount().DeriveAccounts(mnemonic, passphrase, pathTemplate, from, count)
+}
+
 func (o *EvmClient) AccountWithPrivateKey(privateKey string) (account *model.Account, err error) {
 	return model.NewAccount().AccountWithPrivateKey(privateKey)
 }
@@ -59,242 +142,1864 @@ func (o *EvmClient) AccountGenKeystore(privateKey, pwd, path string) (address st
 	return model.NewAccount().AccountGenKeystore(privateKey, pwd, path)
 }
 
-func (o *EvmClient) TokenBalanceOf(address string) (balance string, err error) {
-	chain, err := o.Chain()
-	if err != nil {
-		return "", err
-	}
-	token := model.NewToken(chain)
-	return token.BalanceOf(address)
+// AccountGenKeystoreWithScrypt
+//
+//	@Description: generate keystore file with standard or light scrypt params
+//	@receiver o
+//	@param privateKey
+//	@param pwd
+//	@param path keystore dir
+//	@param scrypt model.KeystoreScryptStandard or model.KeystoreScryptLight
+//	@return address
+//	@return err
+func (o *EvmClient) AccountGenKeystoreWithScrypt(privateKey, pwd, path string, scrypt model.KeystoreScrypt) (address string, err error) {
+	return model.NewAccount().AccountGenKeystoreWithScrypt(privateKey, pwd, path, scrypt)
 }
 
-// TokenEstimateGasLimit
+// AccountFromKeystore
 //
-//	@Description: 估算gas ，如果是合约地址，data肯定不得为空
+//	@Description: decrypt the keystore json
 //	@receiver o
-//	@param fromAddress
-//	@param receiverAddress
-//	@param gasPrice
-//	@param amount
-//	@param data
-//	@return balance
+//	@param keyJSON
+//	@param pwd
+//	@return account
 //	@return err
-func (o *EvmClient) TokenEstimateGasLimit(fromAddress, receiverAddress, gasPrice, amount string, data []byte) (balance string, err error) {
-	chain, err := o.Chain()
-	if err != nil {
-		return "", err
-	}
-	token := model.NewToken(chain)
-	return token.EstimateGasLimit(fromAddress, receiverAddress, gasPrice, amount, data)
+func (o *EvmClient) AccountFromKeystore(keyJSON []byte, pwd string) (account *model.Account, err error) {
+	return model.NewAccount().AccountFromKeystore(keyJSON, pwd)
 }
 
-func (o *EvmClient) Chain() (*model.Chain, error) {
-	return model.GetChain(o.RpcUrl, o.timeout)
+// AccountFromKeystoreFile
+//
+//	@Description: read and decrypt the keystore file
+//	@receiver o
+//	@param path
+//	@param pwd
+//	@return account
+//	@return err
+func (o *EvmClient) AccountFromKeystoreFile(path, pwd string) (account *model.Account, err error) {
+	return model.NewAccount().AccountFromKeystoreFile(path, pwd)
 }
 
-func (o *EvmClient) Nonce(address string) (nonce uint64, err error) {
-	chain, err := o.Chain()
-	if err != nil {
-		return 0, err
-	}
-	return chain.Nonce(address)
+// KeystoreChangePassword
+//
+//	@Description: re-encrypt the keystore file with a new password in place
+//	@receiver o
+//	@param path
+//	@param oldPwd
+//	@param newPwd
+//	@param scrypt
+//	@return error
+func (o *EvmClient) KeystoreChangePassword(path, oldPwd, newPwd string, scrypt model.KeystoreScrypt) error {
+	return model.NewAccount().KeystoreChangePassword(path, oldPwd, newPwd, scrypt)
 }
 
-func (o *EvmClient) TokenTransfer(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
-	chain, err := o.Chain()
+func (o *EvmClient) TokenBalanceOf(address string) (balance string, err error) {
+	return o.TokenBalanceOfCtx(context.Background(), address)
+}
+
+func (o *EvmClient) TokenBalanceOfCtx(ctx context.Context, address string) (balance string, err error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return "", err
 	}
 	token := model.NewToken(chain)
-	return token.Transfer(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+	return token.BalanceOfCtx(ctx, address)
 }
 
-// TxByBlockNumber
+// BalancesOf
 //
-//	@Description: get all tx by block number
+//	@Description: the balances of many addresses by json rpc batch requests
 //	@receiver o
-//	@param number
-//	@return []model.Transaction
+//	@param addresses
+//	@param blockNumber 0 is the latest block
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []model.BalanceResult in the order of addresses, every item has its own error
 //	@return error
-func (o *EvmClient) TxByBlockNumber(number uint64) ([]model.Transaction, error) {
-	chain, err := o.Chain()
+func (o *EvmClient) BalancesOf(addresses []string, blockNumber uint64, batchSize int) ([]model.BalanceResult, error) {
+	return o.BalancesOfCtx(context.Background(), addresses, blockNumber, batchSize)
+}
+
+func (o *EvmClient) BalancesOfCtx(ctx context.Context, addresses []string, blockNumber uint64, batchSize int) ([]model.BalanceResult, error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.TxByBlockNumber(number)
+	return chain.BalancesOfCtx(ctx, addresses, blockNumber, batchSize)
 }
 
-// BlockByNumber
+// NoncesOf
 //
-//	@Description: 读取一个块
+//	@Description: the pending nonces of many addresses by json rpc batch requests
 //	@receiver o
-//	@param number 如果number<=0，则读取最新块
-//	@return *types.Block
+//	@param addresses
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []model.NonceResult in the order of addresses, every item has its own error
 //	@return error
-/*func (o *EvmClient) BlockByNumber(number uint64) (*types.Block, error) {
-	chain, err := o.Chain()
+func (o *EvmClient) NoncesOf(addresses []string, batchSize int) ([]model.NonceResult, error) {
+	return o.NoncesOfCtx(context.Background(), addresses, batchSize)
+}
+
+func (o *EvmClient) NoncesOfCtx(ctx context.Context, addresses []string, batchSize int) ([]model.NonceResult, error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.BlockByNumber(number)
-}*/
+	return chain.NoncesOfCtx(ctx, addresses, batchSize)
+}
 
-// BlockReceiptsByNumber
+// ReceiptsOf
 //
-//	@Description: 读取一个块所有交易的回执
+//	@Description: the receipts of many txs by json rpc batch requests
 //	@receiver o
-//	@param number
-//	@return []*types.Receipt
+//	@param hashes
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []model.ReceiptResult in the order of hashes, the error is ethereum.NotFound if the tx is not mined
 //	@return error
-/*func (o *EvmClient) BlockReceiptsByNumber(number uint64) ([]*types.Receipt, error) {
-	chain, err := o.Chain()
+func (o *EvmClient) ReceiptsOf(hashes []string, batchSize int) ([]model.ReceiptResult, error) {
+	return o.ReceiptsOfCtx(context.Background(), hashes, batchSize)
+}
+
+func (o *EvmClient) ReceiptsOfCtx(ctx context.Context, hashes []string, batchSize int) ([]model.ReceiptResult, error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.BlockReceiptsByNumber(number)
-}*/
+	return chain.ReceiptsOfCtx(ctx, hashes, batchSize)
+}
 
-// TxByHash
+// TokenEstimateGasLimit
 //
-//	@Description: 根据hash获取交易回执
+//	@Description: 估算gas ，如果是合约地址，data肯定不得为空
 //	@receiver o
-//	@param hash
-//	@return *types.Receipt
-//	@return error
-func (o *EvmClient) TxByHash(hash string) (*model.Transaction, error) {
-	chain, err := o.Chain()
+//	@param fromAddress
+//	@param receiverAddress
+//	@param gasPrice
+//	@param amount
+//	@param data
+//	@return balance
+//	@return err
+func (o *EvmClient) TokenEstimateGasLimit(fromAddress, receiverAddress, gasPrice, amount string, data []byte) (balance string, err error) {
+	return o.TokenEstimateGasLimitCtx(context.Background(), fromAddress, receiverAddress, gasPrice, amount, data)
+}
+
+func (o *EvmClient) TokenEstimateGasLimitCtx(ctx context.Context, fromAddress, receiverAddress, gasPrice, amount string, data []byte) (balance string, err error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.TxByHash(hash)
+	token := model.NewToken(chain)
+	return token.EstimateGasLimitCtx(ctx, fromAddress, receiverAddress, gasPrice, amount, data)
 }
 
-// TxIsPending
+// TokenEstimateGasLimitWithAbi
 //
-//	@Description: is pending
+//	@Description: estimate the gas limit of the contract method call, the calldata is packed by the abi,
+//	the abi can be loaded by abiutil.Load, abiutil.LoadFile or abiutil.LoadSignatures
 //	@receiver o
-//	@param hash
-//	@return bool
+//	@param fromAddress
+//	@param contractAddress
+//	@param gasPrice
+//	@param amount
+//	@param contractAbi
+//	@param method
+//	@param args
+//	@return string
 //	@return error
-func (o *EvmClient) TxIsPending(hash string) (bool, error) {
-	chain, err := o.Chain()
+func (o *EvmClient) TokenEstimateGasLimitWithAbi(fromAddress, contractAddress, gasPrice, amount string, contractAbi *abi.ABI, method string, args ...interface{}) (string, error) {
+	return o.TokenEstimateGasLimitWithAbiCtx(context.Background(), fromAddress, contractAddress, gasPrice, amount, contractAbi, method, args...)
+}
+
+func (o *EvmClient) TokenEstimateGasLimitWithAbiCtx(ctx context.Context, fromAddress, contractAddress, gasPrice, amount string, contractAbi *abi.ABI, method string, args ...interface{}) (string, error) {
+	data, err := abiutil.Encode(contractAbi, method, args...)
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.TxIsPending(hash)
+	gasLimit, err := o.TokenEstimateGasLimitCtx(ctx, fromAddress, contractAddress, gasPrice, amount, data)
+	if err != nil {
+		return "", decodeRevertError(err, contractAbi)
+	}
+	return gasLimit, nil
 }
 
-// LatestBlockNumber
+func (o *EvmClient) Chain() (*model.Chain, error) {
+	return o.ChainCtx(context.Background())
+}
+
+// ChainCtx
 //
-//	@Description: 获取最新块
+//	@Description: ctx only controls the dial when the connect is not cached yet
 //	@receiver o
-//	@return uint64
+//	@param ctx
+//	@return *model.Chain
 //	@return error
-func (o *EvmClient) LatestBlockNumber() (uint64, error) {
-	chain, err := o.Chain()
+func (o *EvmClient) ChainCtx(ctx context.Context) (*model.Chain, error) {
+	if len(o.RpcUrls) > 0 {
+		return model.GetChainWithPoolCtx(ctx, o.RpcUrls, o.timeout, o.poolOptions)
+	}
+	return model.GetChainCtx(ctx, o.RpcUrl, o.timeout)
+}
+
+// Close
+//
+//	@Description: close the connect of the client and remove it from the cache
+//	@receiver o
+func (o *EvmClient) Close() {
+	if len(o.RpcUrls) > 0 {
+		model.CloseChain(o.RpcUrls...)
+		return
+	}
+	model.CloseChain(o.RpcUrl)
+}
+
+func (o *EvmClient) Nonce(address string) (nonce uint64, err error) {
+	return o.NonceCtx(context.Background(), address)
+}
+
+func (o *EvmClient) NonceCtx(ctx context.Context, address string) (nonce uint64, err error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return 0, err
 	}
-	transaction := model.NewTransaction(chain)
-	return transaction.LatestBlockNumber()
+	return chain.NonceCtx(ctx, address)
 }
 
-// MetamaskSignLogin
+func (o *EvmClient) EstimateFees() (*types.FeeSuggestion, error) {
+	return o.EstimateFeesCtx(context.Background())
+}
+
+// EstimateFeesCtx
 //
-//	@Description: metamask sign login
+//	@Description: slow/standard/fast fees from eth_feeHistory and eth_maxPriorityFeePerGas
 //	@receiver o
-//	@param message
-//	@param privateKey
-//	@return string
+//	@param ctx
+//	@return *types.FeeSuggestion
 //	@return error
-func (o *EvmClient) MetamaskSignLogin(message, privateKey string) (string, error) {
-	if message == "" || privateKey == "" {
-		return "", errors.New("param is empty")
+func (o *EvmClient) EstimateFeesCtx(ctx context.Context) (*types.FeeSuggestion, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return signutil.MetamaskSignLogin(message, privateKey)
+	return chain.EstimateFeesCtx(ctx)
 }
 
-// SignEip721
+// TokenTransfer
 //
-//	@Description: eip721 sign
+//	@Description: transfer, if gasPrice and maxPriorityFeePerGas are empty, the fees are filled by the fee oracle
 //	@receiver o
 //	@param privateKey
-//	@param typedData
-//	@return string
-//	@return error
-func (o *EvmClient) SignEip721(privateKey string, typedData *apitypes.TypedData) (string, error) {
-	if typedData == nil || privateKey == "" {
-		return "", errors.New("param is empty")
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
+//	@param to
+//	@param data
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenTransfer(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	return o.TokenTransferCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
+
+func (o *EvmClient) TokenTransferCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
 	}
-	return signutil.SignEip721(privateKey, typedData)
+	token := model.NewToken(chain)
+	return token.TransferCtx(ctx, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
 }
 
-// TokenErc20BalanceOf
+func (o *EvmClient) TokenTransferWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	return o.TokenTransferWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
+
+// TokenTransferWithSignerCtx
 //
-//	@Description: erc20 balance
+//	@Description: transfer and sign the transaction with any signer, eg: signer.NewKeystoreSigner, signer.NewClefSigner
 //	@receiver o
-//	@param address user's account address
-//	@param contractAddress erc20 address
-//	@opts options
-//	@return balance
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
+//	@param to
+//	@param data
+//	@return hash
 //	@return err
-func (o *EvmClient) TokenErc20BalanceOf(address, contractAddress string, opts *bind.CallOpts) (balance string, err error) {
-	chain, err := o.Chain()
+func (o *EvmClient) TokenTransferWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return "", err
 	}
+	token := model.NewToken(chain)
+	return token.TransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
 
-	link, err := erc20.NewERC20(common.HexToAddress(contractAddress), chain.RemoteRpcClient)
-	b, err := link.BalanceOf(opts, common.HexToAddress(address))
+// TokenTransferWithAbi
+//
+//	@Description: call the contract method by a transfer, the data is packed by the abi instead of the raw hex,
+//	the abi can be loaded by abiutil.Load, abiutil.LoadFile or abiutil.LoadSignatures
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
+//	@param contractAddress
+//	@param contractAbi
+//	@param method
+//	@param args go values, eg: common.Address, *big.Int, use abiutil.EncodeJSON for the json arguments
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenTransferWithAbi(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress string, contractAbi *abi.ABI, method string, args ...interface{}) (hash string, err error) {
+	return o.TokenTransferWithAbiCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress, contractAbi, method, args...)
+}
+
+func (o *EvmClient) TokenTransferWithAbiCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress string, contractAbi *abi.ABI, method string, args ...interface{}) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
 	if err != nil {
 		return "", err
 	}
-	return b.String(), nil
+	return o.TokenTransferWithAbiSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress, contractAbi, method, args...)
 }
 
-// TokenErc20Approve
+// TokenTransferWithAbiSigner
 //
-//	@Description: approve erc20
+//	@Description: the same as TokenTransferWithAbi, sign with any signer
 //	@receiver o
-//	@param spenderAddress
-//	@param approveValue
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
 //	@param contractAddress
-//	@param opts
-//	@return tx
+//	@param contractAbi
+//	@param method
+//	@param args go values, eg: common.Address, *big.Int, use abiutil.EncodeJSON for the json arguments
+//	@return hash
 //	@return err
-/*func (o *EvmClient) TokenErc20Approve(privateKey, spenderAddress, approveValue, contractAddress string, opts *bind.TransactOpts) (tx string, err error) {
-	chain, err := o.Chain()
+func (o *EvmClient) TokenTransferWithAbiSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress string, contractAbi *abi.ABI, method string, args ...interface{}) (hash string, err error) {
+	return o.TokenTransferWithAbiSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress, contractAbi, method, args...)
+}
+
+func (o *EvmClient) TokenTransferWithAbiSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress string, contractAbi *abi.ABI, method string, args ...interface{}) (hash string, err error) {
+	data, err := abiutil.Encode(contractAbi, method, args...)
 	if err != nil {
 		return "", err
 	}
-	var value *big.Int
-	var valid bool
-	if value, valid = big.NewInt(0).SetString(approveValue, 10); !valid {
-		return "", errors.New("invalid approve value")
-	}
-	if value.Cmp(big.NewInt(0)) <= 0 {
-		return "", errors.New("value need bigger than 0")
+	hash, err = o.TokenTransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress, hexutil.Encode(data))
+	if err != nil {
+		return "", decodeRevertError(err, contractAbi)
 	}
+	return hash, nil
+}
 
-	if opts == nil {
-		opts = &bind.TransactOpts{}
+// decodeRevertError
+//
+//	@Description: the revert error of the chain is decoded without abi, decode its data again by contractAbi for the custom errors
+//	@param err
+//	@param contractAbi
+//	@return error
+func decodeRevertError(err error, contractAbi *abi.ABI) error {
+	var revertErr *model.RevertError
+	if errors.As(err, &revertErr) && len(revertErr.Data) > 0 {
+		decoded := model.DecodeRevert(revertErr.Data, contractAbi)
+		decoded.Err = revertErr.Err
+		return decoded
 	}
+	return err
+}
+
+// SpeedUp
+//
+//	@Description: replace a pending tx by the same tx with higher fees
+//	@receiver o
+//	@param hash the pending tx
+//	@param s the signer of the pending tx
+//	@param bumpPercent the fees raised in percent, at least config.TxReplaceMinBumpPercent
+//	@return string the hash of the new tx
+//	@return error
+func (o *EvmClient) SpeedUp(hash string, s signer.Signer, bumpPercent int64) (string, error) {
+	return o.SpeedUpCtx(context.Background(), hash, s, bumpPercent)
+}
 
-	singer, err := contract.Signer(privateKey, chain.ChainId)
+func (o *EvmClient) SpeedUpCtx(ctx context.Context, hash string, s signer.Signer, bumpPercent int64) (string, error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return "", err
 	}
-	opts.Signer = singer
+	token := model.NewToken(chain)
+	return token.SpeedUpCtx(ctx, hash, s, bumpPercent)
+}
+
+// Cancel
+//
+//	@Description: cancel a pending tx by a 0 value transfer to the sender self with the same nonce
+//	@receiver o
+//	@param hash the pending tx
+//	@param s the signer of the pending tx
+//	@return string the hash of the new tx
+//	@return error
+func (o *EvmClient) Cancel(hash string, s signer.Signer) (string, error) {
+	return o.CancelCtx(context.Background(), hash, s)
+}
 
-	link, err := erc20.NewERC20(common.HexToAddress(contractAddress), chain.RemoteRpcClient)
-	b, err := link.Approve(opts, common.HexToAddress(spenderAddress), value)
+func (o *EvmClient) CancelCtx(ctx context.Context, hash string, s signer.Signer) (string, error) {
+	chain, err := o.ChainCtx(ctx)
 	if err != nil {
 		return "", err
 	}
-	return b.Hash().String(), nil
-}*/
+	token := model.NewToken(chain)
+	return token.CancelCtx(ctx, hash, s)
+}
+
+// TxByBlockNumber
+//
+//	@Description: get all tx by block number
+//	@receiver o
+//	@param number
+//	@return []model.Transaction
+//	@return error
+func (o *EvmClient) TxByBlockNumber(number uint64) ([]model.Transaction, error) {
+	return o.TxByBlockNumberCtx(context.Background(), number)
+}
+
+func (o *EvmClient) TxByBlockNumberCtx(ctx context.Context, number uint64) ([]model.Transaction, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.TxByBlockNumberCtx(ctx, number)
+}
+
+// TxByBlockRange
+//
+//	@Description: get all tx of the blocks from the block to the block, the blocks are read concurrently
+//	@receiver o
+//	@param from
+//	@param to
+//	@param concurrency the max blocks read at the same time, 0 is config.BlockRangeConcurrency
+//	@return []model.Transaction in the order of the chain
+//	@return error
+func (o *EvmClient) TxByBlockRange(from, to uint64, concurrency int) ([]model.Transaction, error) {
+	return o.TxByBlockRangeCtx(context.Background(), from, to, concurrency)
+}
+
+func (o *EvmClient) TxByBlockRangeCtx(ctx context.Context, from, to uint64, concurrency int) ([]model.Transaction, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.TxByBlockRangeCtx(ctx, from, to, concurrency)
+}
+
+// BlockByNumber
+//
+//	@Description: 读取一个块，包含交易hash
+//	@receiver o
+//	@param number 如果number<=0，则读取最新块，创世块使用 model.BlockTagEarliest
+//	@return *model.Block
+//	@return error ethereum.NotFound if the block doesn't exist
+func (o *EvmClient) BlockByNumber(number uint64) (*model.Block, error) {
+	return o.BlockByNumberCtx(context.Background(), number)
+}
+
+func (o *EvmClient) BlockByNumberCtx(ctx context.Context, number uint64) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.BlockByNumberCtx(ctx, number)
+}
+
+// BlockByHash
+//
+//	@Description: 根据hash读取一个块
+//	@receiver o
+//	@param hash
+//	@return *model.Block
+//	@return error
+func (o *EvmClient) BlockByHash(hash string) (*model.Block, error) {
+	return o.BlockByHashCtx(context.Background(), hash)
+}
+
+func (o *EvmClient) BlockByHashCtx(ctx context.Context, hash string) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.BlockByHashCtx(ctx, hash)
+}
+
+// BlockByTag
+//
+//	@Description: 读取指定标签的块
+//	@receiver o
+//	@param tag model.BlockTagLatest, model.BlockTagPending, model.BlockTagSafe, model.BlockTagFinalized, model.BlockTagEarliest
+//	@return *model.Block the pending block has no Hash and Miner
+//	@return error
+func (o *EvmClient) BlockByTag(tag model.BlockTag) (*model.Block, error) {
+	return o.BlockByTagCtx(context.Background(), tag)
+}
+
+func (o *EvmClient) BlockByTagCtx(ctx context.Context, tag model.BlockTag) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.BlockByTagCtx(ctx, tag)
+}
+
+// HeaderByNumber
+//
+//	@Description: 读取块头，不解析交易hash，节点仍会返回交易hash列表（json rpc没有只读块头的方法）
+//	@receiver o
+//	@param number 如果number<=0，则读取最新块，创世块使用 model.BlockTagEarliest
+//	@return *model.Block TxHashes is nil
+//	@return error
+func (o *EvmClient) HeaderByNumber(number uint64) (*model.Block, error) {
+	return o.HeaderByNumberCtx(context.Background(), number)
+}
+
+func (o *EvmClient) HeaderByNumberCtx(ctx context.Context, number uint64) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.HeaderByNumberCtx(ctx, number)
+}
+
+func (o *EvmClient) HeaderByHash(hash string) (*model.Block, error) {
+	return o.HeaderByHashCtx(context.Background(), hash)
+}
+
+func (o *EvmClient) HeaderByHashCtx(ctx context.Context, hash string) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.HeaderByHashCtx(ctx, hash)
+}
+
+func (o *EvmClient) HeaderByTag(tag model.BlockTag) (*model.Block, error) {
+	return o.HeaderByTagCtx(context.Background(), tag)
+}
+
+func (o *EvmClient) HeaderByTagCtx(ctx context.Context, tag model.BlockTag) (*model.Block, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.HeaderByTagCtx(ctx, tag)
+}
+
+// BlockReceiptsByNumber
+//
+//	@Description: 读取一个块所有交易的回执，优先使用 eth_getBlockReceipts，节点不支持时批量读取
+//	@receiver o
+//	@param number 如果number<=0，则读取最新块
+//	@return []*eTypes.Receipt in the order of the txs
+//	@return error
+func (o *EvmClient) BlockReceiptsByNumber(number uint64) ([]*eTypes.Receipt, error) {
+	return o.BlockReceiptsByNumberCtx(context.Background(), number)
+}
+
+func (o *EvmClient) BlockReceiptsByNumberCtx(ctx context.Context, number uint64) ([]*eTypes.Receipt, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.BlockReceiptsByNumberCtx(ctx, number)
+}
+
+// TxByHash
+//
+//	@Description: 根据hash获取交易回执
+//	@receiver o
+//	@param hash
+//	@return *types.Receipt
+//	@return error
+func (o *EvmClient) TxByHash(hash string) (*model.Transaction, error) {
+	return o.TxByHashCtx(context.Background(), hash)
+}
+
+func (o *EvmClient) TxByHashCtx(ctx context.Context, hash string) (*model.Transaction, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.TxByHashCtx(ctx, hash)
+}
+
+// WaitForReceipt
+//
+//	@Description: wait until the tx has enough confirmations, reorgs are followed
+//	@receiver o
+//	@param ctx the waiting is only stopped by ctx
+//	@param hash
+//	@param confirmations
+//	@return *model.Transaction Status is success, reverted, dropped or replaced
+//	@return error
+func (o *EvmClient) WaitForReceipt(ctx context.Context, hash string, confirmations uint64) (*model.Transaction, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.WaitForReceipt(ctx, hash, confirmations)
+}
+
+// WaitForSignedTx
+//
+//	@Description: the same as WaitForReceipt, the replacement is found even if the node has never seen the tx
+//	@receiver o
+//	@param ctx
+//	@param signedTx eg: the SignedTx of the BuildTxResult
+//	@param confirmations
+//	@return *model.Transaction
+//	@return error
+func (o *EvmClient) WaitForSignedTx(ctx context.Context, signedTx *eTypes.Transaction, confirmations uint64) (*model.Transaction, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.WaitForSignedTx(ctx, signedTx, confirmations)
+}
+
+// ReplayFailedTx
+//
+//	@Description: run the failed tx again on the state before its block to recover the revert reason
+//	@receiver o
+//	@param hash
+//	@param abiJson abi of the contract to decode the custom errors, can be empty
+//	@return *model.RevertError
+//	@return error
+func (o *EvmClient) ReplayFailedTx(hash, abiJson string) (*model.RevertError, error) {
+	return o.ReplayFailedTxCtx(context.Background(), hash, abiJson)
+}
+
+func (o *EvmClient) ReplayFailedTxCtx(ctx context.Context, hash, abiJson string) (*model.RevertError, error) {
+	var contractAbi *abi.ABI
+	if abiJson != "" {
+		parsed, err := abiutil.Load(abiJson)
+		if err != nil {
+			return nil, err
+		}
+		contractAbi = parsed
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.ReplayFailedTxCtx(ctx, hash, contractAbi)
+}
+
+// TxIsPending
+//
+//	@Description: is pending
+//	@receiver o
+//	@param hash
+//	@return bool
+//	@return error
+func (o *EvmClient) TxIsPending(hash string) (bool, error) {
+	return o.TxIsPendingCtx(context.Background(), hash)
+}
+
+func (o *EvmClient) TxIsPendingCtx(ctx context.Context, hash string) (bool, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return false, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.TxIsPendingCtx(ctx, hash)
+}
+
+// LatestBlockNumber
+//
+//	@Description: 获取最新块
+//	@receiver o
+//	@return uint64
+//	@return error
+func (o *EvmClient) LatestBlockNumber() (uint64, error) {
+	return o.LatestBlockNumberCtx(context.Background())
+}
+
+func (o *EvmClient) LatestBlockNumberCtx(ctx context.Context) (uint64, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	transaction := model.NewTransaction(chain)
+	return transaction.LatestBlockNumberCtx(ctx)
+}
+
+// MetamaskSignLogin
+//
+//	@Description: metamask sign login
+//	@receiver o
+//	@param message
+//	@param privateKey
+//	@return string
+//	@return error
+func (o *EvmClient) MetamaskSignLogin(message, privateKey string) (string, error) {
+	if message == "" || privateKey == "" {
+		return "", errors.New("param is empty")
+	}
+	return signutil.MetamaskSignLogin(message, privateKey)
+}
+
+func (o *EvmClient) MetamaskSignLoginWithSigner(message string, s signer.Signer) (string, error) {
+	return o.MetamaskSignLoginWithSignerCtx(context.Background(), message, s)
+}
+
+// MetamaskSignLoginWithSignerCtx
+//
+//	@Description: metamask sign login with any signer
+//	@receiver o
+//	@param ctx
+//	@param message
+//	@param s
+//	@return string
+//	@return error
+func (o *EvmClient) MetamaskSignLoginWithSignerCtx(ctx context.Context, message string, s signer.Signer) (string, error) {
+	if message == "" || s == nil {
+		return "", errors.New("param is empty")
+	}
+	return signutil.MetamaskSignLoginWithSigner(ctx, message, s)
+}
+
+// SignEip721
+//
+//	@Description: eip721 sign
+//	@receiver o
+//	@param privateKey
+//	@param typedData
+//	@return string
+//	@return error
+func (o *EvmClient) SignEip721(privateKey string, typedData *apitypes.TypedData) (string, error) {
+	if typedData == nil || privateKey == "" {
+		return "", errors.New("param is empty")
+	}
+	return signutil.SignEip721(privateKey, typedData)
+}
+
+func (o *EvmClient) SignEip721WithSigner(s signer.Signer, typedData *apitypes.TypedData) (string, error) {
+	return o.SignEip721WithSignerCtx(context.Background(), s, typedData)
+}
+
+// SignEip721WithSignerCtx
+//
+//	@Description: eip721 sign with any signer
+//	@receiver o
+//	@param ctx
+//	@param s
+//	@param typedData
+//	@return string
+//	@return error
+func (o *EvmClient) SignEip721WithSignerCtx(ctx context.Context, s signer.Signer, typedData *apitypes.TypedData) (string, error) {
+	if typedData == nil || s == nil {
+		return "", errors.New("param is empty")
+	}
+	return signutil.SignEip721WithSigner(ctx, s, typedData)
+}
+
+// TokenErc20BalanceOf
+//
+//	@Description: erc20 balance
+//	@receiver o
+//	@param address user's account address
+//	@param contractAddress erc20 address
+//	@opts options
+//	@return balance
+//	@return err
+func (o *EvmClient) TokenErc20BalanceOf(address, contractAddress string, opts *bind.CallOpts) (balance string, err error) {
+	return o.TokenErc20BalanceOfCtx(context.Background(), address, contractAddress, opts)
+}
+
+// TokenErc20BalanceOfCtx
+//
+//	@Description: erc20 balance, ctx is used when opts has no context
+//	@receiver o
+//	@param ctx
+//	@param address user's account address
+//	@param contractAddress erc20 address
+//	@opts options
+//	@return balance
+//	@return err
+func (o *EvmClient) TokenErc20BalanceOfCtx(ctx context.Context, address, contractAddress string, opts *bind.CallOpts) (balance string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	callOpts := &bind.CallOpts{}
+	if opts != nil {
+		*callOpts = *opts
+	}
+	if callOpts.Context == nil {
+		callOpts.Context = ctx
+	}
+	callCtx, cancel := chain.WithTimeout(callOpts.Context)
+	defer cancel()
+	callOpts.Context = callCtx
+
+	link, err := erc20.NewERC20(common.HexToAddress(contractAddress), chain.Client())
+	if err != nil {
+		return "", err
+	}
+	b, err := link.BalanceOf(callOpts, common.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// TokenErc20Metadata
+//
+//	@Description: name, symbol and decimals of erc20, cached after the first query
+//	@receiver o
+//	@param contractAddress
+//	@return model.TokenMetadata
+//	@return error
+func (o *EvmClient) TokenErc20Metadata(contractAddress string) (model.TokenMetadata, error) {
+	return o.TokenErc20MetadataCtx(context.Background(), contractAddress)
+}
+
+func (o *EvmClient) TokenErc20MetadataCtx(ctx context.Context, contractAddress string) (model.TokenMetadata, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return model.TokenMetadata{}, err
+	}
+	return model.NewErc20(chain, contractAddress).MetadataCtx(ctx)
+}
+
+// TokenErc20BalanceOfDecimal
+//
+//	@Description: erc20 balance in human amount, eg: 1.5 instead of 1500000
+//	@receiver o
+//	@param address
+//	@param contractAddress
+//	@param opts
+//	@return decimal.Decimal
+//	@return error
+func (o *EvmClient) TokenErc20BalanceOfDecimal(address, contractAddress string, opts *bind.CallOpts) (decimal.Decimal, error) {
+	return o.TokenErc20BalanceOfDecimalCtx(context.Background(), address, contractAddress, opts)
+}
+
+func (o *EvmClient) TokenErc20BalanceOfDecimalCtx(ctx context.Context, address, contractAddress string, opts *bind.CallOpts) (decimal.Decimal, error) {
+	balance, err := o.TokenErc20BalanceOfCtx(ctx, address, contractAddress, opts)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return model.NewErc20(chain, contractAddress).FormatAmountCtx(ctx, balance)
+}
+
+// TokenErc20BalancesOf
+//
+//	@Description: erc20 balances of many owners in many tokens, by multicall3 in one eth_call
+//	@receiver o
+//	@param owners
+//	@param tokens erc20 addresses
+//	@param opts options
+//	@return [][]model.BalanceResult result[i][j] is the balance of owners[i] in tokens[j], every item has its own error
+//	@return error
+func (o *EvmClient) TokenErc20BalancesOf(owners, tokens []string, opts *bind.CallOpts) ([][]model.BalanceResult, error) {
+	return o.TokenErc20BalancesOfCtx(context.Background(), owners, tokens, opts)
+}
+
+func (o *EvmClient) TokenErc20BalancesOfCtx(ctx context.Context, owners, tokens []string, opts *bind.CallOpts) ([][]model.BalanceResult, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	erc20Abi, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([]contract.Call, 0, len(owners)*len(tokens))
+	for _, owner := range owners {
+		for _, token := range tokens {
+			call, err := contract.NewCall(common.HexToAddress(token), erc20Abi, true, "balanceOf", common.HexToAddress(owner))
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+		}
+	}
+
+	callOpts := &bind.CallOpts{}
+	if opts != nil {
+		*callOpts = *opts
+	}
+	if callOpts.Context == nil {
+		callOpts.Context = ctx
+	}
+	callCtx, cancel := chain.WithTimeout(callOpts.Context)
+	defer cancel()
+	callOpts.Context = callCtx
+
+	results, err := contract.NewChainMulticall(chain).Aggregate(callOpts, calls)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([][]model.BalanceResult, len(owners))
+	for i, owner := range owners {
+		balances[i] = make([]model.BalanceResult, len(tokens))
+		for j := range tokens {
+			result := results[i*len(tokens)+j]
+			balances[i][j] = model.BalanceResult{Address: owner, Err: result.Err}
+			if result.Err == nil {
+				if len(result.Values) == 1 {
+					if balance, ok := result.Values[0].(*big.Int); ok {
+						balances[i][j].Balance = balance.String()
+						continue
+					}
+				}
+				balances[i][j].Err = errors.New("invalid balance result")
+			}
+		}
+	}
+	return balances, nil
+}
+
+// TokenErc20Transfer
+//
+//	@Description: transfer erc20, the nonce, fees and gas limit are filled like TokenTransfer if they are empty
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param to
+//	@param amount the min unit of the token
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20Transfer(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc20TransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+// TokenErc20TransferWithSigner
+//
+//	@Description: the same as TokenErc20Transfer, sign with any signer, eg: signer.NewKeystoreSigner, signer.NewClefSigner
+//	@receiver o
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param to
+//	@param amount the min unit of the token
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20TransferWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	token, err := o.erc20(ctx, contractAddress)
+	if err != nil {
+		return "", err
+	}
+	return token.TransferCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount)
+}
+
+// TokenErc20TransferDecimal
+//
+//	@Description: transfer erc20 in human amount, eg: "1.5", it is converted by the decimals of the token
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param to
+//	@param amount
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20TransferDecimal(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferDecimalCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferDecimalCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc20TransferDecimalWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+// TokenErc20TransferDecimalWithSigner
+//
+//	@Description: the same as TokenErc20TransferDecimal, sign with any signer
+//	@receiver o
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param to
+//	@param amount eg: "1.5"
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20TransferDecimalWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferDecimalWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferDecimalWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount, contractAddress string) (hash string, err error) {
+	token, err := o.erc20(ctx, contractAddress)
+	if err != nil {
+		return "", err
+	}
+	value, err := token.ParseAmountCtx(ctx, amount)
+	if err != nil {
+		return "", err
+	}
+	return token.TransferCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, value)
+}
+
+// TokenErc20Approve
+//
+//	@Description: approve erc20, amount 0 revokes the allowance
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param spender
+//	@param amount
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20Approve(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20ApproveCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20ApproveCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc20ApproveWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress)
+}
+
+// TokenErc20ApproveWithSigner
+//
+//	@Description: the same as TokenErc20Approve, sign with any signer
+//	@receiver o
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param spender
+//	@param amount
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20ApproveWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20ApproveWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20ApproveWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount, contractAddress string) (hash string, err error) {
+	token, err := o.erc20(ctx, contractAddress)
+	if err != nil {
+		return "", err
+	}
+	return token.ApproveCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount)
+}
+
+// TokenErc20TransferFrom
+//
+//	@Description: transfer the erc20 of from by the allowance of the private key
+//	@receiver o
+//	@param privateKey the spender
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param amount
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20TransferFrom(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferFromCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferFromCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc20TransferFromWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress)
+}
+
+// TokenErc20TransferFromWithSigner
+//
+//	@Description: the same as TokenErc20TransferFrom, sign with any signer
+//	@receiver o
+//	@param s the spender
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param amount
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc20TransferFromWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress string) (hash string, err error) {
+	return o.TokenErc20TransferFromWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20TransferFromWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount, contractAddress string) (hash string, err error) {
+	token, err := o.erc20(ctx, contractAddress)
+	if err != nil {
+		return "", err
+	}
+	return token.TransferFromCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount)
+}
+
+// TokenErc20Permit
+//
+//	@Description: sign the eip2612 permit of the owner, the permit is submitted by the private key of submitter if it is not empty
+//	@receiver o
+//	@param ownerPrivateKey
+//	@param submitterPrivateKey empty only signs the permit
+//	@param spender
+//	@param amount
+//	@param deadline unix second
+//	@param contractAddress
+//	@return *model.PermitSignature
+//	@return hash empty if the permit is not submitted
+//	@return err
+func (o *EvmClient) TokenErc20Permit(ownerPrivateKey, submitterPrivateKey, spender, amount string, deadline int64, contractAddress string) (permit *model.PermitSignature, hash string, err error) {
+	return o.TokenErc20PermitCtx(context.Background(), ownerPrivateKey, submitterPrivateKey, spender, amount, deadline, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20PermitCtx(ctx context.Context, ownerPrivateKey, submitterPrivateKey, spender, amount string, deadline int64, contractAddress string) (permit *model.PermitSignature, hash string, err error) {
+	owner, err := signer.NewPrivateKeySigner(ownerPrivateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	var submitter signer.Signer
+	if submitterPrivateKey != "" {
+		if submitter, err = signer.NewPrivateKeySigner(submitterPrivateKey); err != nil {
+			return nil, "", err
+		}
+	}
+	return o.TokenErc20PermitWithSignerCtx(ctx, owner, submitter, spender, amount, deadline, contractAddress)
+}
+
+// TokenErc20PermitWithSigner
+//
+//	@Description: the same as TokenErc20Permit, the owner and the submitter can be any signers
+//	@receiver o
+//	@param owner
+//	@param submitter nil only signs the permit
+//	@param spender
+//	@param amount
+//	@param deadline unix second
+//	@param contractAddress
+//	@return *model.PermitSignature
+//	@return hash empty if the permit is not submitted
+//	@return err
+func (o *EvmClient) TokenErc20PermitWithSigner(owner, submitter signer.Signer, spender, amount string, deadline int64, contractAddress string) (permit *model.PermitSignature, hash string, err error) {
+	return o.TokenErc20PermitWithSignerCtx(context.Background(), owner, submitter, spender, amount, deadline, contractAddress)
+}
+
+func (o *EvmClient) TokenErc20PermitWithSignerCtx(ctx context.Context, owner, submitter signer.Signer, spender, amount string, deadline int64, contractAddress string) (permit *model.PermitSignature, hash string, err error) {
+	token, err := o.erc20(ctx, contractAddress)
+	if err != nil {
+		return nil, "", err
+	}
+	permit, err = token.SignPermitCtx(ctx, owner, spender, amount, deadline)
+	if err != nil || submitter == nil {
+		return permit, "", err
+	}
+	hash, err = token.PermitCtx(ctx, submitter, "", "", "", "", permit)
+	return permit, hash, err
+}
+
+func (o *EvmClient) erc20(ctx context.Context, contractAddress string) (*model.Erc20, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewErc20(chain, contractAddress), nil
+}
+
+// ContractSupportsInterface
+//
+//	@Description: erc165 supportsInterface, a contract without erc165 is false
+//	@receiver o
+//	@param contractAddress
+//	@param interfaceId eg: model.InterfaceIdErc721
+//	@return bool
+//	@return error
+func (o *EvmClient) ContractSupportsInterface(contractAddress string, interfaceId [4]byte) (bool, error) {
+	return o.ContractSupportsInterfaceCtx(context.Background(), contractAddress, interfaceId)
+}
+
+func (o *EvmClient) ContractSupportsInterfaceCtx(ctx context.Context, contractAddress string, interfaceId [4]byte) (bool, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return false, err
+	}
+	return model.NewErc165(chain, contractAddress).SupportsInterfaceCtx(ctx, interfaceId)
+}
+
+// NftStandard
+//
+//	@Description: detect the nft standard of the contract by erc165
+//	@receiver o
+//	@param contractAddress
+//	@return model.NftStandard erc721, erc1155, or empty if it is neither
+//	@return error
+func (o *EvmClient) NftStandard(contractAddress string) (model.NftStandard, error) {
+	return o.NftStandardCtx(context.Background(), contractAddress)
+}
+
+func (o *EvmClient) NftStandardCtx(ctx context.Context, contractAddress string) (model.NftStandard, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return model.NftStandardUnknown, err
+	}
+	return model.NewErc165(chain, contractAddress).StandardCtx(ctx)
+}
+
+// TokenErc721OwnerOf
+//
+//	@Description: the owner of the erc721 token id
+//	@receiver o
+//	@param tokenId
+//	@param contractAddress
+//	@return string
+//	@return error
+func (o *EvmClient) TokenErc721OwnerOf(tokenId, contractAddress string) (string, error) {
+	return o.TokenErc721OwnerOfCtx(context.Background(), tokenId, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721OwnerOfCtx(ctx context.Context, tokenId, contractAddress string) (string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc721(chain, contractAddress).OwnerOfCtx(ctx, tokenId)
+}
+
+// TokenErc721BalanceOf
+//
+//	@Description: the count of the erc721 tokens of the owner
+//	@receiver o
+//	@param owner
+//	@param contractAddress
+//	@return string
+//	@return error
+func (o *EvmClient) TokenErc721BalanceOf(owner, contractAddress string) (string, error) {
+	return o.TokenErc721BalanceOfCtx(context.Background(), owner, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721BalanceOfCtx(ctx context.Context, owner, contractAddress string) (string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc721(chain, contractAddress).BalanceOfCtx(ctx, owner)
+}
+
+// TokenErc721TokenURI
+//
+//	@Description: the metadata uri of the erc721 token id
+//	@receiver o
+//	@param tokenId
+//	@param contractAddress
+//	@return string
+//	@return error
+func (o *EvmClient) TokenErc721TokenURI(tokenId, contractAddress string) (string, error) {
+	return o.TokenErc721TokenURICtx(context.Background(), tokenId, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721TokenURICtx(ctx context.Context, tokenId, contractAddress string) (string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc721(chain, contractAddress).TokenURICtx(ctx, tokenId)
+}
+
+// TokenErc721SafeTransferFrom
+//
+//	@Description: erc721 safeTransferFrom, the nonce, fees and gas limit are filled like TokenTransfer if they are empty
+//	@receiver o
+//	@param privateKey the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param tokenId
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc721SafeTransferFrom(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc721SafeTransferFromCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721SafeTransferFromCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc721SafeTransferFromWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress)
+}
+
+// TokenErc721SafeTransferFromWithSigner
+//
+//	@Description: the same as TokenErc721SafeTransferFrom, sign with any signer
+//	@receiver o
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param tokenId
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc721SafeTransferFromWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc721SafeTransferFromWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721SafeTransferFromWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data, contractAddress string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc721(chain, contractAddress).SafeTransferFromCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data)
+}
+
+// TokenErc721SetApprovalForAll
+//
+//	@Description: approve or revoke the operator for all erc721 tokens of the private key
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc721SetApprovalForAll(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	return o.TokenErc721SetApprovalForAllCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721SetApprovalForAllCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc721SetApprovalForAllWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+// TokenErc721SetApprovalForAllWithSigner
+//
+//	@Description: the same as TokenErc721SetApprovalForAll, sign with any signer
+//	@receiver o
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc721SetApprovalForAllWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	return o.TokenErc721SetApprovalForAllWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+func (o *EvmClient) TokenErc721SetApprovalForAllWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc721(chain, contractAddress).SetApprovalForAllCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved)
+}
+
+// TokenErc1155BalanceOf
+//
+//	@Description: the erc1155 balance of the token id of the owner
+//	@receiver o
+//	@param owner
+//	@param id
+//	@param contractAddress
+//	@return string
+//	@return error
+func (o *EvmClient) TokenErc1155BalanceOf(owner, id, contractAddress string) (string, error) {
+	return o.TokenErc1155BalanceOfCtx(context.Background(), owner, id, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155BalanceOfCtx(ctx context.Context, owner, id, contractAddress string) (string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc1155(chain, contractAddress).BalanceOfCtx(ctx, owner, id)
+}
+
+// TokenErc1155BalanceOfBatch
+//
+//	@Description: erc1155 balanceOfBatch, the balance of owners[i] in ids[i]
+//	@receiver o
+//	@param owners
+//	@param ids
+//	@param contractAddress
+//	@return []string
+//	@return error
+func (o *EvmClient) TokenErc1155BalanceOfBatch(owners, ids []string, contractAddress string) ([]string, error) {
+	return o.TokenErc1155BalanceOfBatchCtx(context.Background(), owners, ids, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155BalanceOfBatchCtx(ctx context.Context, owners, ids []string, contractAddress string) ([]string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewErc1155(chain, contractAddress).BalanceOfBatchCtx(ctx, owners, ids)
+}
+
+// TokenErc1155URI
+//
+//	@Description: the metadata uri of the erc1155 token id, "{id}" is replaced
+//	@receiver o
+//	@param id
+//	@param contractAddress
+//	@return string
+//	@return error
+func (o *EvmClient) TokenErc1155URI(id, contractAddress string) (string, error) {
+	return o.TokenErc1155URICtx(context.Background(), id, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155URICtx(ctx context.Context, id, contractAddress string) (string, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc1155(chain, contractAddress).URICtx(ctx, id)
+}
+
+// TokenErc1155SafeTransferFrom
+//
+//	@Description: erc1155 safeTransferFrom, the nonce, fees and gas limit are filled like TokenTransfer if they are empty
+//	@receiver o
+//	@param privateKey the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param id
+//	@param amount
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SafeTransferFrom(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SafeTransferFromCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SafeTransferFromCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc1155SafeTransferFromWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress)
+}
+
+// TokenErc1155SafeTransferFromWithSigner
+//
+//	@Description: the same as TokenErc1155SafeTransferFrom, sign with any signer
+//	@receiver o
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param id
+//	@param amount
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SafeTransferFromWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SafeTransferFromWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SafeTransferFromWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data, contractAddress string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc1155(chain, contractAddress).SafeTransferFromCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data)
+}
+
+// TokenErc1155SafeBatchTransferFrom
+//
+//	@Description: erc1155 safeBatchTransferFrom, many token ids in one tx
+//	@receiver o
+//	@param privateKey the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param ids
+//	@param amounts the same length as ids
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SafeBatchTransferFrom(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to string, ids, amounts []string, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SafeBatchTransferFromCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, ids, amounts, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SafeBatchTransferFromCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to string, ids, amounts []string, data, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc1155SafeBatchTransferFromWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, ids, amounts, data, contractAddress)
+}
+
+// TokenErc1155SafeBatchTransferFromWithSigner
+//
+//	@Description: the same as TokenErc1155SafeBatchTransferFrom, sign with any signer
+//	@receiver o
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param ids
+//	@param amounts the same length as ids
+//	@param data hex, can be empty
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SafeBatchTransferFromWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to string, ids, amounts []string, data, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SafeBatchTransferFromWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, ids, amounts, data, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SafeBatchTransferFromWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to string, ids, amounts []string, data, contractAddress string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc1155(chain, contractAddress).SafeBatchTransferFromCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, ids, amounts, data)
+}
+
+// TokenErc1155SetApprovalForAll
+//
+//	@Description: approve or revoke the operator for all erc1155 tokens of the private key
+//	@receiver o
+//	@param privateKey
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SetApprovalForAll(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SetApprovalForAllCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SetApprovalForAllCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return o.TokenErc1155SetApprovalForAllWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+// TokenErc1155SetApprovalForAllWithSigner
+//
+//	@Description: the same as TokenErc1155SetApprovalForAll, sign with any signer
+//	@receiver o
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@param contractAddress
+//	@return hash
+//	@return err
+func (o *EvmClient) TokenErc1155SetApprovalForAllWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	return o.TokenErc1155SetApprovalForAllWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved, contractAddress)
+}
+
+func (o *EvmClient) TokenErc1155SetApprovalForAllWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool, contractAddress string) (hash string, err error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewErc1155(chain, contractAddress).SetApprovalForAllCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator, approved)
+}
+
+// CallContract
+//
+//	@Description: call the contract method at the latest block without an abigen binding
+//	@receiver o
+//	@param contractAddress
+//	@param abiJson the json abi, or a hardhat/foundry artifact
+//	@param method
+//	@param args go values, eg: common.Address, *big.Int
+//	@return []interface{} the decoded outputs in order
+//	@return error
+func (o *EvmClient) CallContract(contractAddress, abiJson, method string, args ...interface{}) ([]interface{}, error) {
+	return o.CallContractAtCtx(context.Background(), contractAddress, abiJson, model.BlockTagLatest, method, args...)
+}
+
+func (o *EvmClient) CallContractCtx(ctx context.Context, contractAddress, abiJson, method string, args ...interface{}) ([]interface{}, error) {
+	return o.CallContractAtCtx(ctx, contractAddress, abiJson, model.BlockTagLatest, method, args...)
+}
+
+// CallContractAt
+//
+//	@Description: call the contract method at the block
+//	@receiver o
+//	@param contractAddress
+//	@param abiJson
+//	@param block model.BlockTagLatest, model.BlockTagPending, model.BlockTagSafe, model.BlockTagFinalized, or model.BlockTagNumber(n)
+//	@param method
+//	@param args
+//	@return []interface{}
+//	@return error
+func (o *EvmClient) CallContractAt(contractAddress, abiJson string, block model.BlockTag, method string, args ...interface{}) ([]interface{}, error) {
+	return o.CallContractAtCtx(context.Background(), contractAddress, abiJson, block, method, args...)
+}
+
+func (o *EvmClient) CallContractAtCtx(ctx context.Context, contractAddress, abiJson string, block model.BlockTag, method string, args ...interface{}) ([]interface{}, error) {
+	contractAbi, err := abiutil.Load(abiJson)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewContract(chain, contractAddress, contractAbi).CallCtx(ctx, block, method, args...)
+}
+
+// SendContractTx
+//
+//	@Description: build, estimate, sign and send the tx of the contract method without an abigen binding,
+//	the nonce and the fees are filled like TokenTransfer
+//	@receiver o
+//	@param s
+//	@param contractAddress
+//	@param abiJson the json abi, or a hardhat/foundry artifact
+//	@param method
+//	@param value the wei sent to a payable method, empty is 0
+//	@param args go values, eg: common.Address, *big.Int
+//	@return hash
+//	@return err
+func (o *EvmClient) SendContractTx(s signer.Signer, contractAddress, abiJson, method, value string, args ...interface{}) (hash string, err error) {
+	return o.SendContractTxCtx(context.Background(), s, contractAddress, abiJson, method, value, args...)
+}
+
+func (o *EvmClient) SendContractTxCtx(ctx context.Context, s signer.Signer, contractAddress, abiJson, method, value string, args ...interface{}) (hash string, err error) {
+	contractAbi, err := abiutil.Load(abiJson)
+	if err != nil {
+		return "", err
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	return model.NewContract(chain, contractAddress, contractAbi).SendCtx(ctx, s, "", "", "", "", value, method, args...)
+}
+
+// DeployContract
+//
+//	@Description: deploy the contract and wait until it is mined, the nonce and the fees are filled like TokenTransfer
+//	@receiver o
+//	@param s
+//	@param bytecode hex, the creation bytecode without constructor args
+//	@param abiJson the json abi, or a hardhat/foundry artifact, can be empty if the constructor has no args
+//	@param args the constructor args, go values, eg: common.Address, *big.Int
+//	@return address the deployed contract
+//	@return hash
+//	@return err
+func (o *EvmClient) DeployContract(s signer.Signer, bytecode, abiJson string, args ...interface{}) (address, hash string, err error) {
+	return o.DeployContractCtx(context.Background(), s, bytecode, abiJson, args...)
+}
+
+func (o *EvmClient) DeployContractCtx(ctx context.Context, s signer.Signer, bytecode, abiJson string, args ...interface{}) (address, hash string, err error) {
+	var contractAbi *abi.ABI
+	if abiJson != "" {
+		contractAbi, err = abiutil.Load(abiJson)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	token := model.NewToken(chain)
+	address, hash, err = token.DeployCtx(ctx, s, "", "", "", "", "0", bytecode, contractAbi, args...)
+	if err != nil {
+		return "", hash, err
+	}
+	if _, err = token.WaitDeployedCtx(ctx, address, hash, 1); err != nil {
+		return "", hash, err
+	}
+	return address, hash, nil
+}
+
+// NextContractAddress
+//
+//	@Description: the address of the contract if the next tx of the address is a deployment, the pending nonce is used
+//	@receiver o
+//	@param address
+//	@return string
+//	@return error
+func (o *EvmClient) NextContractAddress(address string) (string, error) {
+	return o.NextContractAddressCtx(context.Background(), address)
+}
+
+func (o *EvmClient) NextContractAddressCtx(ctx context.Context, address string) (string, error) {
+	if !util.IsValidAddress(address) {
+		return "", errors.New("address format is error")
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	callCtx, cancel := chain.WithTimeout(ctx)
+	defer cancel()
+	nonce, err := chain.Client().PendingNonceAt(callCtx, common.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+	return util.CreateAddress(address, nonce)
+}
+
+// FilterLogs
+//
+//	@Description: query the logs and decode them by the abis, the large block ranges are split automatically
+//	@receiver o
+//	@param query the addresses, the topics and the block range, ToBlock 0 is the latest block
+//	@param abiJsons the json abis or the hardhat/foundry artifacts, the logs are decoded by the first abi which has the event
+//	@return []model.EventLog in the order of the chain, Event is empty if the log is not decoded
+//	@return error
+func (o *EvmClient) FilterLogs(query model.LogQuery, abiJsons ...string) ([]model.EventLog, error) {
+	return o.FilterLogsCtx(context.Background(), query, abiJsons...)
+}
+
+func (o *EvmClient) FilterLogsCtx(ctx context.Context, query model.LogQuery, abiJsons ...string) ([]model.EventLog, error) {
+	abis := make([]*abi.ABI, 0, len(abiJsons))
+	for _, abiJson := range abiJsons {
+		contractAbi, err := abiutil.Load(abiJson)
+		if err != nil {
+			return nil, err
+		}
+		abis = append(abis, contractAbi)
+	}
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.FilterLogsCtx(ctx, query, abis...)
+}
+
+// NewScanner
+//
+//	@Description: a block scanner which calls the handlers for the blocks, the txs and the logs,
+//	the checkpoint is saved by options.Store and the handlers are rolled back on reorgs
+//	@receiver o
+//	@param options
+//	@return *model.Scanner call Run to follow the head, or Scan to scan once
+//	@return error
+func (o *EvmClient) NewScanner(options model.ScannerOptions) (*model.Scanner, error) {
+	return o.NewScannerCtx(context.Background(), options)
+}
+
+func (o *EvmClient) NewScannerCtx(ctx context.Context, options model.ScannerOptions) (*model.Scanner, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewScanner(chain, options), nil
+}
+
+// NewDepositWatcher
+//
+//	@Description: watch the native and erc20 deposits to the addresses, the addresses can be changed while it runs
+//	@receiver o
+//	@param options
+//	@return *model.DepositWatcher call Run, the deposits go to OnDeposit or the channel of Deposits
+//	@return error
+func (o *EvmClient) NewDepositWatcher(options model.DepositWatcherOptions) (*model.DepositWatcher, error) {
+	return o.NewDepositWatcherCtx(context.Background(), options)
+}
+
+func (o *EvmClient) NewDepositWatcherCtx(ctx context.Context, options model.DepositWatcherOptions) (*model.DepositWatcher, error) {
+	chain, err := o.ChainCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewDepositWatcher(chain, options)
+}
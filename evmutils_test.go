@@ -4,13 +4,21 @@
 package evmutils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/bitxx/evm-utils/model"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util/abiutil"
 	"github.com/bitxx/evm-utils/util/dateutil"
 	"github.com/bitxx/evm-utils/util/httputil"
 	"github.com/bitxx/evm-utils/util/idgenutil"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/status-im/keycard-go/hexutils"
+	"math/big"
 	"math/rand"
 	"strconv"
 	"time"
@@ -19,6 +27,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -63,12 +72,80 @@ func TestAccountWithPrivateKey(t *testing.T) {
 	require.Equal(t, account1.PublicKey, account2.PublicKey)
 }
 
+func TestDeriveAccounts(t *testing.T) {
+	mnemonic := "test test test test test test test test test test test junk"
+	accounts, err := MyClient().DeriveAccounts(mnemonic, "", config.DerivationPathBip44, 0, 3)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(accounts))
+	require.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", accounts[0].Address)
+	require.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", accounts[1].Address)
+	require.Equal(t, "m/44'/60'/0'/0/2", accounts[2].DerivationPath)
+
+	account, err := MyClient().AccountInfoByMnemonic(mnemonic)
+	require.Nil(t, err)
+	require.Equal(t, accounts[0].PrivateKey, account.PrivateKey)
+
+	ledger, err := MyClient().DeriveAccounts(mnemonic, "", config.DerivationPathLedgerLive, 1, 1)
+	require.Nil(t, err)
+	require.Equal(t, "m/44'/60'/1'/0/0", ledger[0].DerivationPath)
+	require.NotEqual(t, accounts[1].Address, ledger[0].Address)
+}
+
+func TestAccountByMnemonicWithOptions(t *testing.T) {
+	for _, language := range []model.MnemonicLanguage{model.MnemonicEnglish, model.MnemonicChineseSimplified, model.MnemonicJapanese, model.MnemonicKorean, model.MnemonicSpanish} {
+		account, err := MyClient().AccountByMnemonicWithOptions(24, language)
+		require.Nil(t, err)
+		require.Nil(t, MyClient().ValidateMnemonic(account.Mnemonic, language))
+		t.Log(language, ": ", account.Mnemonic, " ", account.Address)
+	}
+
+	_, err := MyClient().AccountByMnemonicWithOptions(13, model.MnemonicEnglish)
+	require.True(t, errors.Is(err, model.ErrMnemonicWordCount))
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	require.Nil(t, MyClient().ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", ""))
+
+	err := MyClient().ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "")
+	require.True(t, errors.Is(err, model.ErrMnemonicChecksum))
+
+	_, err = MyClient().AccountInfoByMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandn about")
+	var mnemonicErr *model.MnemonicError
+	require.True(t, errors.As(err, &mnemonicErr))
+	require.Equal(t, 10, mnemonicErr.Index)
+	require.Equal(t, "abandn", mnemonicErr.Word)
+}
+
 func TestAccountGenKeystore(t *testing.T) {
 	address, err := MyClient().AccountGenKeystore(testAccountFromAddressPrivateKey, "123456", "./keystore")
 	require.Nil(t, err)
 	t.Log(address)
 }
 
+func TestAccountKeystore(t *testing.T) {
+	dir := t.TempDir()
+	address, err := MyClient().AccountGenKeystoreWithScrypt(testAccountFromAddressPrivateKey, "123456", dir, model.KeystoreScryptLight)
+	require.Nil(t, err)
+	require.Equal(t, testAccountFromAddress, address)
+
+	files, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(files))
+	path := filepath.Join(dir, files[0].Name())
+
+	account, err := MyClient().AccountFromKeystoreFile(path, "123456")
+	require.Nil(t, err)
+	require.Equal(t, testAccountFromAddressPrivateKey, account.PrivateKey)
+
+	err = MyClient().KeystoreChangePassword(path, "123456", "654321", model.KeystoreScryptLight)
+	require.Nil(t, err)
+	_, err = MyClient().AccountFromKeystoreFile(path, "123456")
+	require.NotNil(t, err)
+	account, err = MyClient().AccountFromKeystoreFile(path, "654321")
+	require.Nil(t, err)
+	require.Equal(t, testAccountFromAddress, account.Address)
+}
+
 func TestTokenBalance(t *testing.T) {
 	balance, err := MyClient().TokenBalanceOf(testAccountToAddress)
 	require.Nil(t, err)
@@ -103,6 +180,27 @@ func TestBatchTokenBalance(t *testing.T) {
 	}
 }
 
+func TestBalancesOf(t *testing.T) {
+	bytes, err := os.ReadFile(addressFile)
+	require.Nil(t, err)
+	var addresses []string
+	for _, address := range strings.Split(string(bytes), "\n") {
+		address = strings.TrimSpace(address)
+		if len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+	}
+	results, err := MyClient().BalancesOf(addresses, 0, 0)
+	require.Nil(t, err)
+	for i, result := range results {
+		if result.Err != nil {
+			t.Error(fmt.Sprintf("index: %d ,address:%s, request err: %s", i, result.Address, result.Err.Error()))
+			continue
+		}
+		t.Log(fmt.Sprintf("address: %s,balance: %s", result.Address, result.Balance))
+	}
+}
+
 func TestNonce(t *testing.T) {
 	nonce, err := MyClient().Nonce(testAccountToAddress)
 	require.Nil(t, err)
@@ -125,6 +223,57 @@ func TestTokenTransfer(t *testing.T) {
 	t.Log("hash:", hash)
 }
 
+func TestTokenTransferWithSigner(t *testing.T) {
+	value := "1000000000000000000"
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	hash, err := MyClient().TokenTransferWithSigner(s, "", config.DefaultEvmGasPrice, config.DefaultEvmGasLimit, "", value, testAccountToAddress, "")
+	require.Nil(t, err)
+	t.Log("hash:", hash)
+}
+
+func TestEstimateFees(t *testing.T) {
+	fees, err := MyClient().EstimateFees()
+	require.Nil(t, err)
+	t.Log(fmt.Sprintf("base fee: %s, slow: %+v, standard: %+v, fast: %+v", fees.BaseFee, fees.Slow, fees.Standard, fees.Fast))
+}
+
+func TestTokenTransferWithFeeOracle(t *testing.T) {
+	value := "1000000000000000000"
+	hash, err := MyClient().TokenTransfer(testAccountFromAddressPrivateKey, "", "", config.DefaultEvmGasLimit, "", value, testAccountToAddress, "")
+	require.Nil(t, err)
+	t.Log("hash:", hash)
+}
+
+func TestWaitForReceipt(t *testing.T) {
+	value := "1000000000000000000"
+	hash, err := MyClient().TokenTransfer(testAccountFromAddressPrivateKey, "", "", config.DefaultEvmGasLimit, "", value, testAccountToAddress, "")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	tx, err := MyClient().WaitForReceipt(ctx, hash, 3)
+	require.Nil(t, err)
+	require.Equal(t, model.TxStatusSuccess, tx.Status)
+	t.Log(fmt.Sprintf("hash: %s, block: %d, status: %s", tx.Hash, tx.BlockNumber, tx.Status))
+}
+
+func TestSpeedUpAndCancel(t *testing.T) {
+	value := "1000000000000000000"
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	hash, err := MyClient().TokenTransferWithSigner(s, "", "", config.DefaultEvmGasLimit, "", value, testAccountToAddress, "")
+	require.Nil(t, err)
+
+	hash, err = MyClient().SpeedUp(hash, s, 20)
+	require.Nil(t, err)
+	t.Log("speed up hash:", hash)
+
+	hash, err = MyClient().Cancel(hash, s)
+	require.Nil(t, err)
+	t.Log("cancel hash:", hash)
+}
+
 // TestTokenTransferWithContract
 //
 //	@Description: test the contract
@@ -141,6 +290,76 @@ func TestTokenTransferWithContract(t *testing.T) {
 	t.Log("hash:", hash)
 }
 
+func TestTokenTransferWithAbi(t *testing.T) {
+	contractAddress := "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"
+	contractAbi, err := abiutil.LoadSignatures("function transfer(address to, uint256 amount) returns (bool)")
+	require.Nil(t, err)
+	gasLimit, err := MyClient().TokenEstimateGasLimitWithAbi(testAccountFromAddress, contractAddress, config.DefaultEvmGasPrice, "0", contractAbi, "transfer", common.HexToAddress(testAccountToAddress), big.NewInt(1000))
+	require.Nil(t, err)
+
+	hash, err := MyClient().TokenTransferWithAbi(testAccountFromAddressPrivateKey, "", "", gasLimit, "", "0", contractAddress, contractAbi, "transfer", common.HexToAddress(testAccountToAddress), big.NewInt(1000))
+	require.Nil(t, err)
+	t.Log("hash:", hash)
+}
+
+func TestTokenTransferWithAbiSigner(t *testing.T) {
+	contractAbi, err := abiutil.LoadSignatures("function transfer(address to, uint256 amount) returns (bool)")
+	require.Nil(t, err)
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	contractAddress := "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"
+	gasLimit, err := MyClient().TokenEstimateGasLimitWithAbi(testAccountFromAddress, contractAddress, config.DefaultEvmGasPrice, "0", contractAbi, "transfer", common.HexToAddress(testAccountToAddress), big.NewInt(1000))
+	require.Nil(t, err)
+
+	hash, err := MyClient().TokenTransferWithAbiSigner(s, "", "", gasLimit, "", "0", contractAddress, contractAbi, "transfer", common.HexToAddress(testAccountToAddress), big.NewInt(1000))
+	require.Nil(t, err)
+	t.Log("hash:", hash)
+}
+
+func TestCallContract(t *testing.T) {
+	abiJson := `[{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`
+	values, err := MyClient().CallContractAt("0x3E4511645086a6fabECbAf1c3eE152C067f0AedA", abiJson, model.BlockTagFinalized, "balanceOf", common.HexToAddress(testAccountFromAddress))
+	require.Nil(t, err)
+	t.Log("balance: ", values[0])
+}
+
+func TestSendContractTx(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	abiJson := `[{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`
+	hash, err := MyClient().SendContractTx(s, "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA", abiJson, "transfer", "0", common.HexToAddress(testAccountToAddress), big.NewInt(1000))
+	require.Nil(t, err)
+	t.Log("hash: ", hash)
+}
+
+func TestDeployContract(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	client := MyClient()
+	expected, err := client.NextContractAddress(s.Address().Hex())
+	require.Nil(t, err)
+	// the runtime code is one byte: STOP
+	address, hash, err := client.DeployContract(s, "0x6001600c60003960016000f300", "")
+	require.Nil(t, err)
+	require.Equal(t, expected, address)
+	t.Log("address: ", address, " hash: ", hash)
+}
+
+func TestFilterLogs(t *testing.T) {
+	client := MyClient()
+	latest, err := client.LatestBlockNumber()
+	require.Nil(t, err)
+	logs, err := client.FilterLogs(model.LogQuery{
+		Addresses: []string{"0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"},
+		FromBlock: latest - 5000,
+		ToBlock:   latest,
+	}, erc20.ERC20MetaData.ABI)
+	require.Nil(t, err)
+	for _, log := range logs {
+		t.Log(log.BlockNumber, " ", log.TxHash, " ", log.Event, " ", log.Fields)
+	}
+}
+
 func TestBatchTokenTransferToManyAddress(t *testing.T) {
 	privateKey := ""
 	gasLimit := "21000"
@@ -316,12 +535,55 @@ func TestTxs(t *testing.T) {
 	}
 }
 
+func TestTxByBlockRange(t *testing.T) {
+	txs, err := MyClient().TxByBlockRange(1000000, 1000010, 4)
+	require.Nil(t, err)
+	t.Log("count: ", len(txs))
+}
+
+func TestBlockReceiptsByNumber(t *testing.T) {
+	receipts, err := MyClient().BlockReceiptsByNumber(1000000)
+	require.Nil(t, err)
+	for _, receipt := range receipts {
+		t.Log(receipt.TxHash.Hex(), " ", receipt.Status)
+	}
+}
+
+func TestBlockByTag(t *testing.T) {
+	client := MyClient()
+	block, err := client.BlockByTag(model.BlockTagFinalized)
+	require.Nil(t, err)
+	t.Log("finalized: ", block.Number, " ", block.Hash, " ", block.BaseFee, " txs: ", len(block.TxHashes))
+
+	header, err := client.HeaderByHash(block.Hash)
+	require.Nil(t, err)
+	require.Equal(t, block.Number, header.Number)
+
+	block, err = client.BlockByNumber(1000000)
+	require.Nil(t, err)
+	t.Log(block.Hash, " ", block.Time, " ", block.Miner)
+}
+
 func TestLatestBlockNumber(t *testing.T) {
 	number, err := MyClient().LatestBlockNumber()
 	require.Nil(t, err)
 	t.Log(number)
 }
 
+func TestLatestBlockNumberCtx(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	number, err := MyClient().LatestBlockNumberCtx(ctx)
+	require.Nil(t, err)
+	t.Log(number)
+
+	// a cancelled context must stop the rpc call
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = MyClient().LatestBlockNumberCtx(cancelCtx)
+	require.NotNil(t, err)
+}
+
 func TestTxByHash(t *testing.T) {
 	tx, err := MyClient().TxByHash("0xca80de96ff9d64c6894a3daca59d613ff391958599a50ee4ad8ad1d8220f3e06")
 	require.Nil(t, err)
@@ -334,28 +596,87 @@ func TestTokenErc20BalanceOf(t *testing.T) {
 	t.Log(number)
 }
 
+func TestTokenErc20Metadata(t *testing.T) {
+	metadata, err := MyClient().TokenErc20Metadata("0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
+	require.Nil(t, err)
+	t.Log(fmt.Sprintf("name: %s, symbol: %s, decimals: %d", metadata.Name, metadata.Symbol, metadata.Decimals))
+
+	balance, err := MyClient().TokenErc20BalanceOfDecimal(testAccountFromAddress, "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA", nil)
+	require.Nil(t, err)
+	t.Log("balance: ", balance.String())
+}
+
+func TestTokenErc20BalancesOf(t *testing.T) {
+	owners := []string{testAccountFromAddress, testAccountToAddress}
+	tokens := []string{"0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"}
+	balances, err := MyClient().TokenErc20BalancesOf(owners, tokens, nil)
+	require.Nil(t, err)
+	for i, owner := range owners {
+		for j, token := range tokens {
+			require.Nil(t, balances[i][j].Err)
+			t.Log(fmt.Sprintf("owner: %s, token: %s, balance: %s", owner, token, balances[i][j].Balance))
+		}
+	}
+}
+
+func TestNftStandard(t *testing.T) {
+	standard, err := MyClient().NftStandard("0xbd927011759b2c4f2602c3008f8ef3407db53473")
+	require.Nil(t, err)
+	t.Log("standard: ", standard)
+}
+
+func TestTokenErc721OwnerOf(t *testing.T) {
+	owner, err := MyClient().TokenErc721OwnerOf("1", "0xbd927011759b2c4f2602c3008f8ef3407db53473")
+	require.Nil(t, err)
+	t.Log("owner: ", owner)
+}
+
 func TestIsPending(t *testing.T) {
 	isPending, err := MyClient().TxIsPending("0xca80de96ff9d64c6894a3daca59d613ff391958599a50ee4ad8ad1d8220f3e06")
 	require.Nil(t, err)
 	t.Log("pending status: ", isPending)
 }
 
-/*func TestApprove(t *testing.T) {
-	privateKey := ""
-	spenderAddress := ""
-	hash, err := MyClient().TokenErc20Approve(privateKey, spenderAddress, "80000000000000000000", "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA", nil)
+func TestTokenErc20Transfer(t *testing.T) {
+	hash, err := MyClient().TokenErc20Transfer(testAccountFromAddressPrivateKey, "", "", "", "", testAccountToAddress, "1000000000000000000", "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
 	require.Nil(t, err)
 	t.Log("hash: ", hash)
 }
 
 func TestTokenErc20Approve(t *testing.T) {
-	hash, err := MyClient().TokenErc20Approve(testAccountFromAddress, "80000000000000000000", "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA", nil)
+	hash, err := MyClient().TokenErc20Approve(testAccountFromAddressPrivateKey, "", "", "", "", testAccountToAddress, "80000000000000000000", "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
 	require.Nil(t, err)
 	t.Log("hash: ", hash)
-	receipt, err := MyClient().TxReceipt(hash)
+	tx, err := MyClient().WaitForReceipt(context.Background(), hash, 1)
+	require.Nil(t, err)
+	t.Log("status: ", tx.Status)
+}
 
-	receipt.BlockHash.String()
-}*/
+func TestTokenErc20Permit(t *testing.T) {
+	deadline := time.Now().Add(time.Hour).Unix()
+	permit, hash, err := MyClient().TokenErc20Permit(testAccountFromAddressPrivateKey, "", testAccountToAddress, "80000000000000000000", deadline, "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
+	require.Nil(t, err)
+	require.Empty(t, hash)
+	t.Log("signature: ", permit.Signature)
+}
+
+func TestTokenErc20TransferWithSigner(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	hash, err := MyClient().TokenErc20TransferWithSigner(s, "", "", "", "", testAccountToAddress, "1000000000000000000", "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
+	require.Nil(t, err)
+	t.Log("hash: ", hash)
+}
+
+func TestTokenErc20PermitWithSigner(t *testing.T) {
+	owner, err := signer.NewPrivateKeySigner(testAccountFromAddressPrivateKey)
+	require.Nil(t, err)
+	deadline := time.Now().Add(time.Hour).Unix()
+	permit, hash, err := MyClient().TokenErc20PermitWithSigner(owner, nil, testAccountToAddress, "80000000000000000000", deadline, "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA")
+	require.Nil(t, err)
+	require.Empty(t, hash)
+	t.Log("signature: ", permit.Signature)
+}
 
 /*func TestBlock(t *testing.T) {
 	block, err := MyClient().BlockByNumber(6301626)
@@ -11,6 +11,7 @@ require (
 	github.com/storyicon/sigverify v1.1.0
 	github.com/stretchr/testify v1.8.4
 	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -2,19 +2,47 @@ package model
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
 	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
-	"github.com/tyler-smith/go-bip39"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
+type KeystoreScrypt int
+
+const (
+	KeystoreScryptStandard KeystoreScrypt = iota // slow and strong, for the long-term storage
+	KeystoreScryptLight                          // fast, for the tests and the batch imports
+)
+
+// params
+//
+//	@Description: scrypt N and P
+//	@receiver s
+//	@return n
+//	@return p
+func (s KeystoreScrypt) params() (n, p int) {
+	if s == KeystoreScryptLight {
+		return keystore.LightScryptN, keystore.LightScryptP
+	}
+	return keystore.StandardScryptN, keystore.StandardScryptP
+}
+
 type Account struct {
-	Address    string `json:"address"`
-	PrivateKey string `json:"privateKey"`
-	PublicKey  string `json:"publicKey"`
-	Mnemonic   string `json:"mnemonic"`
+	Address        string `json:"address"`
+	PrivateKey     string `json:"privateKey"`
+	PublicKey      string `json:"publicKey"`
+	Mnemonic       string `json:"mnemonic"`
+	DerivationPath string `json:"derivationPath"`
 }
 
 func NewAccount() *Account {
@@ -22,11 +50,19 @@ func NewAccount() *Account {
 }
 
 func (a *Account) AccountByMnemonic() (account *Account, err error) {
-	entropy, err := bip39.NewEntropy(128)
-	if err != nil {
-		return nil, err
-	}
-	mnemonic, err := bip39.NewMnemonic(entropy)
+	return a.AccountByMnemonicWithOptions(12, MnemonicEnglish)
+}
+
+// AccountByMnemonicWithOptions
+//
+//	@Description: generate a new mnemonic account
+//	@receiver a
+//	@param wordCount 12, 15, 18, 21 or 24
+//	@param language the bip39 wordlist
+//	@return account
+//	@return err
+func (a *Account) AccountByMnemonicWithOptions(wordCount int, language MnemonicLanguage) (account *Account, err error) {
+	mnemonic, err := NewMnemonic(wordCount, language)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +71,7 @@ func (a *Account) AccountByMnemonic() (account *Account, err error) {
 
 // AccountInfoByMnemonic
 //
-//	@Description: get mnemonic account info
+//	@Description: get mnemonic account info, the mnemonic is validated before deriving
 //	@receiver d
 //	@param mnemonic
 //	@return address
@@ -43,11 +79,93 @@ func (a *Account) AccountByMnemonic() (account *Account, err error) {
 //	@return publicKey
 //	@return err
 func (a *Account) AccountInfoByMnemonic(mnemonic string) (account *Account, err error) {
-	wallet, err := hdwallet.NewFromSeed(bip39.NewSeed(mnemonic, ""))
+	return a.AccountInfoByMnemonicPath(mnemonic, "", config.DefaultDerivationPath)
+}
+
+// AccountInfoByMnemonicPath
+//
+//	@Description: get mnemonic account info with bip39 passphrase and derivation path
+//	@receiver a
+//	@param mnemonic
+//	@param passphrase bip39 passphrase, empty is allowed
+//	@param path full derivation path, eg: m/44'/60'/0'/0/0
+//	@return account
+//	@return err
+func (a *Account) AccountInfoByMnemonicPath(mnemonic, passphrase, path string) (account *Account, err error) {
+	derivationPath, err := hdwallet.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = ValidateMnemonic(mnemonic, ""); err != nil {
+		return nil, err
+	}
+	wallet, err := hdwallet.NewFromSeed(mnemonicSeed(mnemonic, passphrase))
+	if err != nil {
+		return nil, err
+	}
+	account, err = accountFromWallet(wallet, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	account.Mnemonic = mnemonic
+	return account, nil
+}
+
+// DeriveAccounts
+//
+//	@Description: derive count accounts from one mnemonic, the index of each account is filled into pathTemplate
+//	@receiver a
+//	@param mnemonic
+//	@param passphrase bip39 passphrase, empty is allowed
+//	@param pathTemplate derivation path with one %d as index, eg: config.DerivationPathBip44, config.DerivationPathLedgerLive, config.DerivationPathLegacyMEW
+//	@param from the first index
+//	@param count how many accounts to derive
+//	@return []Account
+//	@return error
+func (a *Account) DeriveAccounts(mnemonic, passphrase, pathTemplate string, from, count uint32) ([]Account, error) {
+	if count == 0 {
+		return nil, errors.New("count must bigger than 0")
+	}
+	if strings.Count(pathTemplate, "%d") != 1 {
+		return nil, errors.New("path template must contain exactly one %d")
+	}
+	if uint64(from)+uint64(count) > math.MaxUint32 {
+		return nil, errors.New("account index out of range")
+	}
+
+	if err := ValidateMnemonic(mnemonic, ""); err != nil {
+		return nil, err
+	}
+	wallet, err := hdwallet.NewFromSeed(mnemonicSeed(mnemonic, passphrase))
 	if err != nil {
 		return nil, err
 	}
-	acc, err := wallet.Derive(hdwallet.MustParseDerivationPath("m/44'/60'/0'/0/0"), false)
+
+	result := make([]Account, 0, count)
+	for i := from; i < from+count; i++ {
+		derivationPath, err := hdwallet.ParseDerivationPath(fmt.Sprintf(pathTemplate, i))
+		if err != nil {
+			return nil, err
+		}
+		account, err := accountFromWallet(wallet, derivationPath)
+		if err != nil {
+			return nil, err
+		}
+		account.Mnemonic = mnemonic
+		result = append(result, *account)
+	}
+	return result, nil
+}
+
+// accountFromWallet
+//
+//	@Description: derive the account of path from the hd wallet
+//	@param wallet
+//	@param path
+//	@return account
+//	@return err
+func accountFromWallet(wallet *hdwallet.Wallet, path accounts.DerivationPath) (account *Account, err error) {
+	acc, err := wallet.Derive(path, false)
 	if err != nil {
 		return nil, err
 	}
@@ -66,10 +184,10 @@ func (a *Account) AccountInfoByMnemonic(mnemonic string) (account *Account, err
 		return nil, err
 	}
 	account = &Account{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-		Mnemonic:   mnemonic,
-		Address:    address,
+		PrivateKey:     privateKey,
+		PublicKey:      publicKey,
+		Address:        address,
+		DerivationPath: path.String(),
 	}
 	return
 }
@@ -104,6 +222,20 @@ func (a *Account) AccountWithPrivateKey(privateKey string) (account *Account, er
 //	@return address
 //	@return err
 func (a *Account) AccountGenKeystore(privateKey, pwd, path string) (address string, err error) {
+	return a.AccountGenKeystoreWithScrypt(privateKey, pwd, path, KeystoreScryptStandard)
+}
+
+// AccountGenKeystoreWithScrypt
+//
+//	@Description: 生成keystore文件，可选scrypt参数
+//	@receiver a
+//	@param privateKey
+//	@param pwd
+//	@param path keystore dir
+//	@param scrypt KeystoreScryptStandard or KeystoreScryptLight
+//	@return address
+//	@return err
+func (a *Account) AccountGenKeystoreWithScrypt(privateKey, pwd, path string, scrypt KeystoreScrypt) (address string, err error) {
 	priData, err := util.HexDecodeString(privateKey)
 	if err != nil {
 		return "", err
@@ -114,11 +246,91 @@ func (a *Account) AccountGenKeystore(privateKey, pwd, path string) (address stri
 		return "", err
 	}
 
-	ks := keystore.NewKeyStore(path, keystore.StandardScryptN, keystore.StandardScryptP)
+	scryptN, scryptP := scrypt.params()
+	ks := keystore.NewKeyStore(path, scryptN, scryptP)
 	acc, err := ks.ImportECDSA(privateKeyECDSA, pwd)
 	if err != nil {
 		return "", err
 	}
 	return acc.Address.Hex(), nil
+}
+
+// AccountFromKeystore
+//
+//	@Description: 解密keystore内容
+//	@receiver a
+//	@param keyJSON the content of the keystore file
+//	@param pwd
+//	@return account
+//	@return err
+func (a *Account) AccountFromKeystore(keyJSON []byte, pwd string) (account *Account, err error) {
+	key, err := keystore.DecryptKey(keyJSON, pwd)
+	if err != nil {
+		return nil, err
+	}
+	return a.AccountWithPrivateKey(hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)))
+}
+
+// AccountFromKeystoreFile
+//
+//	@Description: 读取并解密keystore文件
+//	@receiver a
+//	@param path keystore file
+//	@param pwd
+//	@return account
+//	@return err
+func (a *Account) AccountFromKeystoreFile(path, pwd string) (account *Account, err error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.AccountFromKeystore(keyJSON, pwd)
+}
+
+// KeystoreChangePassword
+//
+//	@Description: 修改keystore文件密码，原文件会被重新加密后替换
+//	@receiver a
+//	@param path keystore file
+//	@param oldPwd
+//	@param newPwd
+//	@param scrypt the scrypt params of the new file
+//	@return error
+func (a *Account) KeystoreChangePassword(path, oldPwd, newPwd string, scrypt KeystoreScrypt) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	key, err := keystore.DecryptKey(keyJSON, oldPwd)
+	if err != nil {
+		return err
+	}
+
+	scryptN, scryptP := scrypt.params()
+	newKeyJSON, err := keystore.EncryptKey(key, newPwd, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
 
+	// write a temp file and rename it, so the old file is never half written
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(newKeyJSON); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,305 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type BalanceResult struct {
+	Address string
+	Balance string
+	Err     error
+}
+
+type NonceResult struct {
+	Address string
+	Nonce   uint64
+	Err     error
+}
+
+type ReceiptResult struct {
+	Hash    string
+	Receipt *types.Receipt
+	Err     error // ethereum.NotFound if the tx is not mined
+}
+
+func (c *Chain) BalancesOf(addresses []string, blockNumber uint64, batchSize int) ([]BalanceResult, error) {
+	return c.BalancesOfCtx(context.Background(), addresses, blockNumber, batchSize)
+}
+
+// BalancesOfCtx
+//
+//	@Description: the balances of many addresses by json rpc batch requests
+//	@receiver c
+//	@param ctx
+//	@param addresses
+//	@param blockNumber 0 is the latest block
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []BalanceResult in the order of addresses, every item has its own error
+//	@return error ctx is done
+func (c *Chain) BalancesOfCtx(ctx context.Context, addresses []string, blockNumber uint64, batchSize int) ([]BalanceResult, error) {
+	block := "latest"
+	if blockNumber > 0 {
+		block = hexutil.EncodeUint64(blockNumber)
+	}
+
+	results := make([]BalanceResult, len(addresses))
+	balances := make([]hexutil.Big, len(addresses))
+	var elems []rpc.BatchElem
+	var indexes []int
+	for i, address := range addresses {
+		results[i].Address = address
+		if !util.IsValidAddress(address) {
+			results[i].Err = errors.New("address format is error")
+			continue
+		}
+		elems = append(elems, rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{common.HexToAddress(address), block},
+			Result: &balances[i],
+		})
+		indexes = append(indexes, i)
+	}
+
+	if err := c.batchCall(ctx, elems, batchSize); err != nil {
+		return nil, err
+	}
+	for j, elem := range elems {
+		i := indexes[j]
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+			continue
+		}
+		results[i].Balance = (*big.Int)(&balances[i]).String()
+	}
+	return results, nil
+}
+
+func (c *Chain) NoncesOf(addresses []string, batchSize int) ([]NonceResult, error) {
+	return c.NoncesOfCtx(context.Background(), addresses, batchSize)
+}
+
+// NoncesOfCtx
+//
+//	@Description: the pending nonces of many addresses by json rpc batch requests
+//	@receiver c
+//	@param ctx
+//	@param addresses
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []NonceResult in the order of addresses, every item has its own error
+//	@return error ctx is done
+func (c *Chain) NoncesOfCtx(ctx context.Context, addresses []string, batchSize int) ([]NonceResult, error) {
+	results := make([]NonceResult, len(addresses))
+	nonces := make([]hexutil.Uint64, len(addresses))
+	var elems []rpc.BatchElem
+	var indexes []int
+	for i, address := range addresses {
+		results[i].Address = address
+		if !util.IsValidAddress(address) {
+			results[i].Err = errors.New("address format is error")
+			continue
+		}
+		elems = append(elems, rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{common.HexToAddress(address), "pending"},
+			Result: &nonces[i],
+		})
+		indexes = append(indexes, i)
+	}
+
+	if err := c.batchCall(ctx, elems, batchSize); err != nil {
+		return nil, err
+	}
+	for j, elem := range elems {
+		i := indexes[j]
+		if elem.Error != nil {
+			results[i].Err = elem.Error
+			continue
+		}
+		results[i].Nonce = uint64(nonces[i])
+	}
+	return results, nil
+}
+
+func (c *Chain) ReceiptsOf(hashes []string, batchSize int) ([]ReceiptResult, error) {
+	return c.ReceiptsOfCtx(context.Background(), hashes, batchSize)
+}
+
+// ReceiptsOfCtx
+//
+//	@Description: the receipts of many txs by json rpc batch requests
+//	@receiver c
+//	@param ctx
+//	@param hashes
+//	@param batchSize max calls of one batch request, 0 is config.RpcBatchSize
+//	@return []ReceiptResult in the order of hashes, every item has its own error
+//	@return error ctx is done
+func (c *Chain) ReceiptsOfCtx(ctx context.Context, hashes []string, batchSize int) ([]ReceiptResult, error) {
+	results := make([]ReceiptResult, len(hashes))
+	receipts := make([]*types.Receipt, len(hashes))
+	elems := make([]rpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		results[i].Hash = hash
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{common.HexToHash(hash)},
+			Result: &receipts[i],
+		}
+	}
+
+	if err := c.batchCall(ctx, elems, batchSize); err != nil {
+		return nil, err
+	}
+	for i, elem := range elems {
+		switch {
+		case elem.Error != nil:
+			results[i].Err = elem.Error
+		case receipts[i] == nil:
+			results[i].Err = ethereum.NotFound
+		default:
+			results[i].Receipt = receipts[i]
+		}
+	}
+	return results, nil
+}
+
+// blockReceipts
+//
+//	@Description: the receipts of all txs of the block by eth_getBlockReceipts, if the node doesn't support it,
+//	by batched eth_getTransactionReceipt, and eth_getBlockReceipts is tried again after config.BlockReceiptsRetryInterval,
+//	so an endpoint of the pool which doesn't support it doesn't disable it for good
+//	@receiver c
+//	@param ctx
+//	@param block
+//	@return []*types.Receipt in the order of the txs
+//	@return error
+func (c *Chain) blockReceipts(ctx context.Context, block *types.Block) ([]*types.Receipt, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return []*types.Receipt{}, nil
+	}
+	unsupported := c.noBlockReceipts.Load()
+	if unsupported == 0 || time.Since(time.Unix(0, unsupported)) > time.Duration(config.BlockReceiptsRetryInterval)*time.Second {
+		callCtx, cancel := c.WithTimeout(ctx)
+		// by the hash, the receipts of a reorged block are never returned
+		receipts, err := c.Client().BlockReceipts(callCtx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+		cancel()
+		switch {
+		case err == nil && len(receipts) == len(txs):
+			c.noBlockReceipts.Store(0)
+			if err = checkReceipts(block, txs, receipts); err != nil {
+				return nil, err
+			}
+			return receipts, nil
+		case err == nil:
+			return nil, fmt.Errorf("eth_getBlockReceipts returns %d receipts for %d txs", len(receipts), len(txs))
+		case isMethodNotFound(err):
+			c.noBlockReceipts.Store(time.Now().UnixNano())
+		default:
+			return nil, err
+		}
+	}
+
+	hashes := make([]string, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash().Hex()
+	}
+	results, err := c.ReceiptsOfCtx(ctx, hashes, 0)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make([]*types.Receipt, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("receipt of %s: %w", result.Hash, result.Err)
+		}
+		receipts[i] = result.Receipt
+	}
+	// by the tx hash, the receipts may be of another block if it is reorged meanwhile
+	if err = checkReceipts(block, txs, receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// checkReceipts
+//
+//	@Description: every receipt must be of its tx in the block
+//	@param block
+//	@param txs
+//	@param receipts in the order of txs
+//	@return error
+func checkReceipts(block *types.Block, txs []*types.Transaction, receipts []*types.Receipt) error {
+	for i, receipt := range receipts {
+		if receipt == nil || receipt.TxHash != txs[i].Hash() {
+			return fmt.Errorf("the receipt of %s doesn't match the tx of block %d", txs[i].Hash().Hex(), block.NumberU64())
+		}
+		if receipt.BlockHash != block.Hash() {
+			return fmt.Errorf("the receipt of %s is of block %s, not %s, the block may be reorged", txs[i].Hash().Hex(), receipt.BlockHash.Hex(), block.Hash().Hex())
+		}
+	}
+	return nil
+}
+
+// isMethodNotFound
+//
+//	@Description: the node doesn't support the rpc method, only -32601 and the messages of it,
+//	eg: "the method eth_getBlockReceipts does not exist/is not available" of geth, not "block ... does not exist" of a lagging node
+//	@param err
+//	@return bool
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "unsupported method") ||
+		(strings.HasPrefix(msg, "the method ") && strings.Contains(msg, "does not exist"))
+}
+
+// batchCall
+//
+//	@Description: send the calls in batches of batchSize, if a whole batch fails, the error is set to all its calls
+//	@receiver c
+//	@param ctx
+//	@param elems
+//	@param batchSize 0 is config.RpcBatchSize
+//	@return error only when ctx is done
+func (c *Chain) batchCall(ctx context.Context, elems []rpc.BatchElem, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = config.RpcBatchSize
+	}
+	for start := 0; start < len(elems); start += batchSize {
+		end := start + batchSize
+		if end > len(elems) {
+			end = len(elems)
+		}
+		batch := elems[start:end]
+
+		_, rpcClient := c.clients()
+		callCtx, cancel := c.WithTimeout(ctx)
+		err := rpcClient.BatchCallContext(callCtx, batch)
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			for i := range batch {
+				batch[i].Error = err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+var testMinedHash = common.HexToHash("0x01")
+
+func (e *ethStub) GetBalance(address common.Address, _ rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if address == (common.Address{}) {
+		return nil, errors.New("balance of zero address is not supported")
+	}
+	return (*hexutil.Big)(new(big.Int).SetBytes(address.Bytes()[19:])), nil
+}
+
+func (e *ethStub) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	if hash != testMinedHash {
+		return nil, nil
+	}
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            hash,
+		GasUsed:           21000,
+		CumulativeGasUsed: 21000,
+		Logs:              []*types.Log{},
+		BlockNumber:       big.NewInt(100),
+	}, nil
+}
+
+func TestBatchQueries(t *testing.T) {
+	ctx := context.Background()
+	chain := newStubChain(t, &ethStub{nonce: 7})
+
+	addresses := []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000000",
+		"bad address",
+		"0x0000000000000000000000000000000000000003",
+	}
+	// many batches
+	balances, err := chain.BalancesOfCtx(ctx, addresses, 0, 2)
+	require.Nil(t, err)
+	require.Len(t, balances, 4)
+	require.Equal(t, "1", balances[0].Balance)
+	require.NotNil(t, balances[1].Err)
+	require.NotNil(t, balances[2].Err)
+	require.Nil(t, balances[3].Err)
+	require.Equal(t, "3", balances[3].Balance)
+
+	nonces, err := chain.NoncesOfCtx(ctx, addresses, 2)
+	require.Nil(t, err)
+	require.Equal(t, uint64(7), nonces[0].Nonce)
+	require.NotNil(t, nonces[2].Err)
+
+	receipts, err := chain.ReceiptsOfCtx(ctx, []string{testMinedHash.Hex(), "0x02"}, 1)
+	require.Nil(t, err)
+	require.Nil(t, receipts[0].Err)
+	require.Equal(t, uint64(100), receipts[0].Receipt.BlockNumber.Uint64())
+	require.ErrorIs(t, receipts[1].Err, ethereum.NotFound)
+}
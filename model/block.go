@@ -0,0 +1,237 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+)
+
+// Block
+//
+//	@Description: a block or a header, the fields which the chain doesn't have are zero,
+//	eg: BaseFee before london, the blob gas before cancun, Hash and Miner of the pending block
+type Block struct {
+	Number        uint64
+	Hash          string
+	ParentHash    string
+	Time          uint64
+	BaseFee       decimal.Decimal
+	GasUsed       uint64
+	GasLimit      uint64
+	Miner         string
+	Difficulty    decimal.Decimal
+	ExtraData     []byte
+	StateRoot     string
+	ReceiptsRoot  string
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+	Size          uint64
+	TxHashes      []string // nil for a header
+}
+
+// rpcHeader
+//
+//	@Description: eth_getBlockByNumber without the full txs, the fields of the pending block can be null
+type rpcHeader struct {
+	Number        *hexutil.Big    `json:"number"`
+	Hash          *common.Hash    `json:"hash"`
+	ParentHash    common.Hash     `json:"parentHash"`
+	Timestamp     hexutil.Uint64  `json:"timestamp"`
+	BaseFee       *hexutil.Big    `json:"baseFeePerGas"`
+	GasUsed       hexutil.Uint64  `json:"gasUsed"`
+	GasLimit      hexutil.Uint64  `json:"gasLimit"`
+	Miner         *common.Address `json:"miner"`
+	Difficulty    *hexutil.Big    `json:"difficulty"`
+	ExtraData     hexutil.Bytes   `json:"extraData"`
+	StateRoot     common.Hash     `json:"stateRoot"`
+	ReceiptsRoot  common.Hash     `json:"receiptsRoot"`
+	BlobGasUsed   *hexutil.Uint64 `json:"blobGasUsed"`
+	ExcessBlobGas *hexutil.Uint64 `json:"excessBlobGas"`
+	Size          hexutil.Uint64  `json:"size"`
+}
+
+// rpcBlock the header with the tx hashes
+type rpcBlock struct {
+	rpcHeader
+	Transactions []common.Hash `json:"transactions"`
+}
+
+func (c *Chain) BlockByTag(tag BlockTag) (*Block, error) {
+	return c.BlockByTagCtx(context.Background(), tag)
+}
+
+// BlockByTagCtx
+//
+//	@Description: the block with the tx hashes
+//	@receiver c
+//	@param ctx
+//	@param tag latest, pending, safe, finalized, earliest or a block number, empty is latest
+//	@return *Block
+//	@return error ethereum.NotFound if the block doesn't exist
+func (c *Chain) BlockByTagCtx(ctx context.Context, tag BlockTag) (*Block, error) {
+	number, err := tag.rpcBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	return c.getBlock(ctx, true, "eth_getBlockByNumber", number.String())
+}
+
+func (c *Chain) BlockByNumber(number uint64) (*Block, error) {
+	return c.BlockByNumberCtx(context.Background(), number)
+}
+
+// BlockByNumberCtx
+//
+//	@Description: 根据编号读取块，包含交易hash
+//	@receiver c
+//	@param ctx
+//	@param number 如果number<=0，则读取最新块，创世块使用 BlockTagEarliest
+//	@return *Block
+//	@return error ethereum.NotFound if the block doesn't exist
+func (c *Chain) BlockByNumberCtx(ctx context.Context, number uint64) (*Block, error) {
+	if number <= 0 {
+		return c.BlockByTagCtx(ctx, BlockTagLatest)
+	}
+	return c.BlockByTagCtx(ctx, BlockTagNumber(number))
+}
+
+func (c *Chain) BlockByHash(hash string) (*Block, error) {
+	return c.BlockByHashCtx(context.Background(), hash)
+}
+
+func (c *Chain) BlockByHashCtx(ctx context.Context, hash string) (*Block, error) {
+	if _, err := hexutil.Decode(hash); err != nil || len(hash) != 66 {
+		return nil, errors.New("block hash format is error")
+	}
+	return c.getBlock(ctx, true, "eth_getBlockByHash", common.HexToHash(hash))
+}
+
+func (c *Chain) HeaderByTag(tag BlockTag) (*Block, error) {
+	return c.HeaderByTagCtx(context.Background(), tag)
+}
+
+// HeaderByTagCtx
+//
+//	@Description: the same as BlockByTagCtx without the tx hashes, they are not decoded.
+//	json rpc has no method of the header only, the node still returns the tx hashes, the same as ethclient.HeaderByNumber
+//	@receiver c
+//	@param ctx
+//	@param tag
+//	@return *Block
+//	@return error
+func (c *Chain) HeaderByTagCtx(ctx context.Context, tag BlockTag) (*Block, error) {
+	number, err := tag.rpcBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	return c.getBlock(ctx, false, "eth_getBlockByNumber", number.String())
+}
+
+func (c *Chain) HeaderByNumber(number uint64) (*Block, error) {
+	return c.HeaderByNumberCtx(context.Background(), number)
+}
+
+// HeaderByNumberCtx
+//
+//	@Description: 根据编号读取块头
+//	@receiver c
+//	@param ctx
+//	@param number 如果number<=0，则读取最新块，创世块使用 BlockTagEarliest
+//	@return *Block
+//	@return error
+func (c *Chain) HeaderByNumberCtx(ctx context.Context, number uint64) (*Block, error) {
+	if number <= 0 {
+		return c.HeaderByTagCtx(ctx, BlockTagLatest)
+	}
+	return c.HeaderByTagCtx(ctx, BlockTagNumber(number))
+}
+
+func (c *Chain) HeaderByHash(hash string) (*Block, error) {
+	return c.HeaderByHashCtx(context.Background(), hash)
+}
+
+func (c *Chain) HeaderByHashCtx(ctx context.Context, hash string) (*Block, error) {
+	if _, err := hexutil.Decode(hash); err != nil || len(hash) != 66 {
+		return nil, errors.New("block hash format is error")
+	}
+	return c.getBlock(ctx, false, "eth_getBlockByHash", common.HexToHash(hash))
+}
+
+// getBlock
+//
+//	@Description: the block without the full txs
+//	@receiver c
+//	@param ctx
+//	@param withTxs decode the tx hashes, false for a header
+//	@param method
+//	@param arg
+//	@return *Block
+//	@return error
+func (c *Chain) getBlock(ctx context.Context, withTxs bool, method string, arg interface{}) (*Block, error) {
+	callCtx, cancel := c.WithTimeout(ctx)
+	defer cancel()
+	var raw json.RawMessage
+	_, rpcClient := c.clients()
+	if err := rpcClient.CallContext(callCtx, &raw, method, arg, false); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	if !withTxs {
+		var header rpcHeader
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return nil, err
+		}
+		return header.toBlock(), nil
+	}
+	var block rpcBlock
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+	result := block.toBlock()
+	result.TxHashes = make([]string, len(block.Transactions))
+	for i, hash := range block.Transactions {
+		result.TxHashes[i] = hash.Hex()
+	}
+	return result, nil
+}
+
+func (b *rpcHeader) toBlock() *Block {
+	block := &Block{
+		ParentHash:   b.ParentHash.Hex(),
+		Time:         uint64(b.Timestamp),
+		GasUsed:      uint64(b.GasUsed),
+		GasLimit:     uint64(b.GasLimit),
+		ExtraData:    b.ExtraData,
+		StateRoot:    b.StateRoot.Hex(),
+		ReceiptsRoot: b.ReceiptsRoot.Hex(),
+		Size:         uint64(b.Size),
+	}
+	if b.Number != nil {
+		block.Number = b.Number.ToInt().Uint64()
+	}
+	if b.Hash != nil {
+		block.Hash = b.Hash.Hex()
+	}
+	if b.BaseFee != nil {
+		block.BaseFee = decimal.NewFromBigInt(b.BaseFee.ToInt(), 0)
+	}
+	if b.Miner != nil {
+		block.Miner = b.Miner.Hex()
+	}
+	if b.Difficulty != nil {
+		block.Difficulty = decimal.NewFromBigInt(b.Difficulty.ToInt(), 0)
+	}
+	if b.BlobGasUsed != nil {
+		block.BlobGasUsed = uint64(*b.BlobGasUsed)
+	}
+	if b.ExcessBlobGas != nil {
+		block.ExcessBlobGas = uint64(*b.ExcessBlobGas)
+	}
+	return block
+}
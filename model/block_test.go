@@ -0,0 +1,118 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+const testBlockHash = "0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6"
+
+// blockStub
+//
+//	@Description: eth_getBlockByNumber and eth_getBlockByHash, the pending block has no hash and miner
+type blockStub struct {
+	tags []string
+}
+
+func (b *blockStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (b *blockStub) GetBlockByNumber(tag string, full bool) (map[string]interface{}, error) {
+	if full {
+		return nil, errors.New("the full txs are not expected")
+	}
+	b.tags = append(b.tags, tag)
+	switch tag {
+	case "0x2710":
+		return nil, nil
+	case "pending":
+		block := testRpcBlock()
+		block["hash"] = nil
+		block["miner"] = nil
+		return block, nil
+	}
+	return testRpcBlock(), nil
+}
+
+func (b *blockStub) GetBlockByHash(hash common.Hash, _ bool) (map[string]interface{}, error) {
+	if hash != common.HexToHash(testBlockHash) {
+		return nil, nil
+	}
+	return testRpcBlock(), nil
+}
+
+func testRpcBlock() map[string]interface{} {
+	return map[string]interface{}{
+		"number":        "0x64",
+		"hash":          testBlockHash,
+		"parentHash":    "0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		"timestamp":     "0x55ba467c",
+		"baseFeePerGas": "0x3b9aca00",
+		"gasUsed":       "0x5208",
+		"gasLimit":      "0x1c9c380",
+		"miner":         testNonceAddress,
+		"difficulty":    "0x0",
+		"extraData":     "0x",
+		"stateRoot":     "0xd7f8974fb5ac78d9ac099b9ad5018bedc2ce0a72dad1827a1709da30580f0544",
+		"receiptsRoot":  "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"blobGasUsed":   "0x20000",
+		"excessBlobGas": "0x0",
+		"size":          "0x21c",
+		"transactions":  []string{"0xca80de96ff9d64c6894a3daca59d613ff391958599a50ee4ad8ad1d8220f3e06"},
+	}
+}
+
+func TestBlockByTag(t *testing.T) {
+	stub := &blockStub{}
+	chain := newStubChain(t, stub)
+
+	for _, tag := range []BlockTag{"", BlockTagPending, BlockTagSafe, BlockTagFinalized, BlockTagEarliest, BlockTagNumber(100)} {
+		_, err := chain.BlockByTagCtx(context.Background(), tag)
+		require.Nil(t, err)
+	}
+	require.Equal(t, []string{"latest", "pending", "safe", "finalized", "earliest", "0x64"}, stub.tags)
+
+	block, err := chain.BlockByNumberCtx(context.Background(), 100)
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), block.Number)
+	require.Equal(t, common.HexToHash(testBlockHash).Hex(), block.Hash)
+	require.Equal(t, "1000000000", block.BaseFee.String())
+	require.Equal(t, uint64(21000), block.GasUsed)
+	require.Equal(t, uint64(30000000), block.GasLimit)
+	require.Equal(t, common.HexToAddress(testNonceAddress).Hex(), block.Miner)
+	require.Equal(t, uint64(131072), block.BlobGasUsed)
+	require.Equal(t, uint64(0x55ba467c), block.Time)
+	require.Len(t, block.TxHashes, 1)
+
+	// 0 is the latest block, the same as TxByBlockNumber
+	stub.tags = nil
+	_, err = chain.BlockByNumberCtx(context.Background(), 0)
+	require.Nil(t, err)
+	_, err = chain.HeaderByNumberCtx(context.Background(), 0)
+	require.Nil(t, err)
+	require.Equal(t, []string{"latest", "latest"}, stub.tags)
+
+	block, err = chain.BlockByTagCtx(context.Background(), BlockTagPending)
+	require.Nil(t, err)
+	require.Equal(t, "", block.Hash)
+	require.Equal(t, "", block.Miner)
+
+	header, err := chain.HeaderByHashCtx(context.Background(), testBlockHash)
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), header.Number)
+	require.Nil(t, header.TxHashes)
+
+	_, err = chain.HeaderByNumberCtx(context.Background(), 10000)
+	require.True(t, errors.Is(err, ethereum.NotFound))
+	_, err = chain.BlockByHashCtx(context.Background(), "0x1234")
+	require.NotNil(t, err)
+	_, err = chain.BlockByTagCtx(context.Background(), "unknown")
+	require.NotNil(t, err)
+}
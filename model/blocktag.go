@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BlockTag the block of a query, a tag or a block number
+type BlockTag string
+
+const (
+	BlockTagLatest    BlockTag = "latest"
+	BlockTagPending   BlockTag = "pending"
+	BlockTagSafe      BlockTag = "safe"
+	BlockTagFinalized BlockTag = "finalized"
+	BlockTagEarliest  BlockTag = "earliest"
+)
+
+// BlockTagNumber
+//
+//	@Description: the block tag of the block number
+//	@param number
+//	@return BlockTag
+func BlockTagNumber(number uint64) BlockTag {
+	return BlockTag(hexutil.EncodeUint64(number))
+}
+
+// BlockNumber
+//
+//	@Description: the block number which ethclient accepts, the tags are negative numbers like rpc.BlockNumber
+//	@receiver t
+//	@return *big.Int nil is the latest block
+//	@return error
+func (t BlockTag) BlockNumber() (*big.Int, error) {
+	number, err := t.rpcBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if number == rpc.LatestBlockNumber {
+		return nil, nil
+	}
+	return big.NewInt(number.Int64()), nil
+}
+
+// rpcBlockNumber
+//
+//	@Description: empty is the latest block, the number can be decimal or 0x hex
+//	@receiver t
+//	@return rpc.BlockNumber
+//	@return error
+func (t BlockTag) rpcBlockNumber() (rpc.BlockNumber, error) {
+	tag := strings.ToLower(strings.TrimSpace(string(t)))
+	switch BlockTag(tag) {
+	case "", BlockTagLatest:
+		return rpc.LatestBlockNumber, nil
+	case BlockTagPending:
+		return rpc.PendingBlockNumber, nil
+	case BlockTagSafe:
+		return rpc.SafeBlockNumber, nil
+	case BlockTagFinalized:
+		return rpc.FinalizedBlockNumber, nil
+	case BlockTagEarliest:
+		return rpc.EarliestBlockNumber, nil
+	}
+	var number uint64
+	var err error
+	if strings.HasPrefix(tag, "0x") {
+		number, err = hexutil.DecodeUint64(tag)
+	} else {
+		number, err = strconv.ParseUint(tag, 10, 64)
+	}
+	if err != nil || number > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid block tag: %s", t)
+	}
+	return rpc.BlockNumber(number), nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/model/signer"
 	"github.com/bitxx/evm-utils/model/types"
 	"github.com/bitxx/evm-utils/util"
 	"github.com/ethereum/go-ethereum/common"
@@ -14,18 +16,33 @@ import (
 	"math/big"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var chainConnections = make(map[string]*Chain)
-var lock sync.RWMutex
-
 type Chain struct {
+	// RemoteRpcClient the calls of a pool or a http url go through Client(), so it is never nil and follows the reconnects and the failover.
+	// for a ws or ipc url it is the client of the connect, the subscriptions work, it is replaced by Reconnect, use Client() to always get the current one
 	RemoteRpcClient *ethclient.Client
 	Timeout         int64
 	rpcClient       *rpc.Client
+	client          *ethclient.Client // the client of rpcClient, nil if the chain is connected by a pool
 	ChainId         *big.Int
 	rpcUrl          string
+	pool            *rpcPool // not nil if the chain is connected by many rpc urls
+	rpcUrls         []string
+	poolOptions     RpcPoolOptions
+
+	key      string // key of the connection registry
+	connLock sync.RWMutex
+	dialLock sync.Mutex // only one reconnect dials at a time, the calls are not blocked by it
+	closed   bool
+	lastUsed int64 // unix nano, atomic
+
+	nonceManager *NonceManager
+	nonceOnce    sync.Once
+
+	noBlockReceipts atomic.Int64 // unix nano, the node answered eth_getBlockReceipts is not found, 0 if it is supported
 }
 
 // GetChain
@@ -36,78 +53,286 @@ type Chain struct {
 //	@return *EthChain
 //	@return error
 func GetChain(rpcUrl string, timeout int64) (*Chain, error) {
+	return GetChainCtx(context.Background(), rpcUrl, timeout)
+}
+
+// GetChainCtx
+//
+//	@Description: get connect from cache, ctx only controls the dial when the connect is not cached
+//	@param ctx
+//	@param rpcUrl
+//	@param timeout
+//	@return *Chain
+//	@return error
+func GetChainCtx(ctx context.Context, rpcUrl string, timeout int64) (*Chain, error) {
 	if rpcUrl == "" {
 		return nil, errors.New("rpc url can't empty")
 	}
+	return loadChain(ctx, rpcUrl, func() (*Chain, error) {
+		return newChain(ctx, rpcUrl, timeout)
+	})
+}
 
-	chain, ok := chainConnections[rpcUrl]
-	if ok {
-		return chain, nil
-	}
-
-	// 通过加锁范围
-	lock.Lock()
-	defer lock.Unlock()
+// GetChainWithPool
+//
+//	@Description: get the connect of many rpc urls of the same chain from cache, the calls go to the healthy ones
+//	@param rpcUrls
+//	@param timeout
+//	@param options
+//	@return *Chain
+//	@return error
+func GetChainWithPool(rpcUrls []string, timeout int64, options RpcPoolOptions) (*Chain, error) {
+	return GetChainWithPoolCtx(context.Background(), rpcUrls, timeout, options)
+}
 
-	// 再判断一次
-	chain, ok = chainConnections[rpcUrl]
-	if ok {
-		return chain, nil
+func GetChainWithPoolCtx(ctx context.Context, rpcUrls []string, timeout int64, options RpcPoolOptions) (*Chain, error) {
+	if len(rpcUrls) == 0 {
+		return nil, errors.New("rpc url can't empty")
 	}
-
-	// 创建并存储
-	chain, err := newChain(rpcUrl, timeout)
-	if err != nil {
-		return nil, err
+	for _, rpcUrl := range rpcUrls {
+		if rpcUrl == "" {
+			return nil, errors.New("rpc url can't empty")
+		}
+	}
+	if len(rpcUrls) == 1 {
+		return GetChainCtx(ctx, rpcUrls[0], timeout)
 	}
 
-	chainConnections[rpcUrl] = chain
-	return chain, nil
+	return loadChain(ctx, chainKey(rpcUrls), func() (*Chain, error) {
+		return newChainWithPool(ctx, rpcUrls, timeout, options)
+	})
 }
 
 // newChain
 //
 //	@Description:
+//	@param ctx
 //	@param timeout the net connect time, second,default is 60
 //	@return *Chain
-func newChain(rpcUrl string, timeout int64) (chain *Chain, err error) {
+func newChain(ctx context.Context, rpcUrl string, timeout int64) (chain *Chain, err error) {
 	if timeout <= 0 {
 		timeout = 60
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := withTimeout(ctx, timeout)
 	defer cancel()
 	rpcClient, err := rpc.DialContext(ctx, rpcUrl)
 	if err != nil {
 		return
 	}
+	return newChainFromClient(ctx, rpcClient, rpcUrl, timeout)
+}
 
+func newChainFromClient(ctx context.Context, rpcClient *rpc.Client, rpcUrl string, timeout int64) (chain *Chain, err error) {
 	remoteRpcClient := ethclient.NewClient(rpcClient)
 	chainId, err := remoteRpcClient.ChainID(ctx)
 	if err != nil {
+		rpcClient.Close()
 		return
 	}
 
 	chain = &Chain{
-		ChainId:         chainId,
-		rpcClient:       rpcClient,
-		RemoteRpcClient: remoteRpcClient,
-		rpcUrl:          rpcUrl,
-		Timeout:         timeout,
+		ChainId:   chainId,
+		rpcClient: rpcClient,
+		client:    remoteRpcClient,
+		rpcUrl:    rpcUrl,
+		Timeout:   timeout,
+		lastUsed:  time.Now().UnixNano(),
+	}
+	if isHttpUrl(rpcUrl) {
+		chain.RemoteRpcClient, err = newRelayClient(chain)
+		if err != nil {
+			rpcClient.Close()
+			return nil, err
+		}
+	} else {
+		chain.RemoteRpcClient = remoteRpcClient
 	}
 	return
 }
 
+// NonceManager
+//
+//	@Description: the nonce manager shared by all txs sent by this chain connect, and by the other connects of the same rpc urls from the cache
+//	@receiver c
+//	@return *NonceManager
+func (c *Chain) NonceManager() *NonceManager {
+	c.nonceOnce.Do(func() {
+		if c.key != "" {
+			c.nonceManager = sharedNonceManager(c)
+			return
+		}
+		c.nonceManager = NewNonceManager(c)
+	})
+	return c.nonceManager
+}
+
+// Client
+//
+//	@Description: the client of the rpc endpoint to call, it is chosen from the pool if there are many rpc urls
+//	@receiver c
+//	@return *ethclient.Client
+func (c *Chain) Client() *ethclient.Client {
+	client, _ := c.clients()
+	return client
+}
+
+// clients
+//
+//	@Description: the eth client and the raw rpc client of the same endpoint
+//	@receiver c
+//	@return *ethclient.Client
+//	@return *rpc.Client
+func (c *Chain) clients() (*ethclient.Client, *rpc.Client) {
+	atomic.StoreInt64(&c.lastUsed, time.Now().UnixNano())
+
+	c.connLock.RLock()
+	closed := c.closed
+	c.connLock.RUnlock()
+	if closed {
+		// closed by the idle eviction or Close, connect again, the calls fail on the closed client if it can't
+		_ = c.reconnect(context.Background(), true)
+	}
+
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	if c.pool == nil {
+		return c.client, c.rpcClient
+	}
+	return c.pool.clients()
+}
+
+// Endpoints
+//
+//	@Description: the health of all rpc endpoints, only the one rpc url if the chain is not connected by a pool
+//	@receiver c
+//	@return []RpcEndpointStatus
+func (c *Chain) Endpoints() []RpcEndpointStatus {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	if c.pool == nil {
+		return []RpcEndpointStatus{{Url: c.rpcUrl, Healthy: !c.closed}}
+	}
+	return c.pool.status()
+}
+
+// Close
+//
+//	@Description: close the connect and remove it from the cache, it connects again and goes back to the cache if it is still used
+//	@receiver c
 func (c *Chain) Close() {
-	if c.RemoteRpcClient != nil {
-		c.RemoteRpcClient.Close()
+	unregisterChain(c)
+	c.close()
+}
+
+func (c *Chain) close() {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.closeConn()
+}
+
+func (c *Chain) closeConn() {
+	if c.pool != nil {
+		c.pool.close()
+		return
 	}
 	if c.rpcClient != nil {
 		c.rpcClient.Close()
 	}
 }
 
+// Reconnect
+//
+//	@Description: dial the rpc urls again and replace the connect in place, the chain id must not change.
+//	the chain goes back to the cache if it was removed by Close or the idle eviction
+//	@receiver c
+//	@param ctx
+//	@return error
+func (c *Chain) Reconnect(ctx context.Context) error {
+	return c.reconnect(ctx, false)
+}
+
+// reconnect
+//
+//	@Description: the dial is done without connLock, so the calls on the old connect are not blocked
+//	@receiver c
+//	@param ctx
+//	@param onlyClosed skip it if another caller has connected the closed chain again
+//	@return error
+func (c *Chain) reconnect(ctx context.Context, onlyClosed bool) error {
+	c.dialLock.Lock()
+	defer c.dialLock.Unlock()
+
+	c.connLock.RLock()
+	closed := c.closed
+	c.connLock.RUnlock()
+	if onlyClosed && !closed {
+		return nil
+	}
+
+	var fresh *Chain
+	var err error
+	if c.rpcUrls != nil {
+		fresh, err = newChainWithPool(ctx, c.rpcUrls, c.Timeout, c.poolOptions)
+	} else {
+		fresh, err = newChain(ctx, c.rpcUrl, c.Timeout)
+	}
+	if err != nil {
+		return err
+	}
+	if fresh.ChainId.Cmp(c.ChainId) != 0 {
+		fresh.closeConn()
+		return fmt.Errorf("the chain id changed from %s to %s", c.ChainId, fresh.ChainId)
+	}
+
+	c.connLock.Lock()
+	if !c.closed {
+		c.closeConn()
+	}
+	if c.RemoteRpcClient == c.client {
+		// the client of a ws or ipc url, the others go through Client()
+		c.RemoteRpcClient = fresh.client
+	}
+	c.client = fresh.client
+	c.rpcClient = fresh.rpcClient
+	c.pool = fresh.pool
+	c.closed = false
+	c.connLock.Unlock()
+
+	registerChain(c)
+	return nil
+}
+
+// WithTimeout
+//
+//	@Description: apply the chain timeout to ctx, only when ctx has no deadline of its own
+//	@receiver c
+//	@param ctx
+//	@return context.Context
+//	@return context.CancelFunc
+func (c *Chain) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.Timeout)
+}
+
+func withTimeout(ctx context.Context, timeout int64) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+}
+
 func (c *Chain) EstimateGasLimit(msg *types.CallMsg) (gas string, err error) {
+	return c.EstimateGasLimitCtx(context.Background(), msg)
+}
+
+func (c *Chain) EstimateGasLimitCtx(ctx context.Context, msg *types.CallMsg) (gas string, err error) {
 
 	if len(msg.Msg.Data) > 0 {
 		// any contract transaction
@@ -117,10 +342,11 @@ func (c *Chain) EstimateGasLimit(msg *types.CallMsg) (gas string, err error) {
 		gas = config.DefaultEvmGasLimit
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout)*time.Second)
+	ctx, cancel := c.WithTimeout(ctx)
 	defer cancel()
-	gasLimit, err := c.RemoteRpcClient.EstimateGas(ctx, msg.Msg)
+	gasLimit, err := c.Client().EstimateGas(ctx, msg.Msg)
 	if err != nil {
+		err = WrapRevertError(err, nil)
 		return
 	}
 	gasString := ""
@@ -137,9 +363,13 @@ func (c *Chain) EstimateGasLimit(msg *types.CallMsg) (gas string, err error) {
 }
 
 func (c *Chain) Nonce(spenderAddressHex string) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout)*time.Second)
+	return c.NonceCtx(context.Background(), spenderAddressHex)
+}
+
+func (c *Chain) NonceCtx(ctx context.Context, spenderAddressHex string) (uint64, error) {
+	ctx, cancel := c.WithTimeout(ctx)
 	defer cancel()
-	nonce, err := c.RemoteRpcClient.PendingNonceAt(ctx, common.HexToAddress(spenderAddressHex))
+	nonce, err := c.Client().PendingNonceAt(ctx, common.HexToAddress(spenderAddressHex))
 	if err != nil {
 		return 0, err
 	}
@@ -148,21 +378,25 @@ func (c *Chain) Nonce(spenderAddressHex string) (uint64, error) {
 
 // BuildTxUnSign
 //
-//	@Description: the transaction no sign
+//	@Description: the transaction no sign, if the nonce is empty, it is got from the nonce manager,
+//	call NonceManager().Release if the tx will not be sent, NonceManager().Sent after sending it
 //	@receiver c
 //	@param privateKey
 //	@param transaction
 //	@return *eTypes.Transaction
 //	@return error
 func (c *Chain) BuildTxUnSign(address string, transaction *types.Transaction) (*eTypes.Transaction, error) {
+	return c.BuildTxUnSignCtx(context.Background(), address, transaction)
+}
+
+func (c *Chain) BuildTxUnSignCtx(ctx context.Context, address string, transaction *types.Transaction) (*eTypes.Transaction, error) {
 	if transaction.Nonce == "" || transaction.Nonce == "0" {
 		if !util.IsValidAddress(address) {
 			return nil, errors.New("address format is error")
 		}
-		nonce, err := c.Nonce(address)
+		nonce, err := c.NonceManager().Next(ctx, address)
 		if err != nil {
-			nonce = 0
-			err = nil
+			return nil, err
 		}
 		transaction.Nonce = strconv.FormatUint(nonce, 10)
 	}
@@ -173,8 +407,28 @@ func (c *Chain) BuildTxSign(privateKey *ecdsa.PrivateKey, txNoSign *eTypes.Trans
 	if privateKey == nil || txNoSign == nil {
 		return nil, errors.New("param is empty")
 	}
+	s, err := signer.NewPrivateKeySignerFromECDSA(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return c.BuildTxSignWithSigner(context.Background(), s, txNoSign)
+}
+
+// BuildTxSignWithSigner
+//
+//	@Description: sign the transaction with any signer, eg: keystore, clef
+//	@receiver c
+//	@param ctx
+//	@param s
+//	@param txNoSign
+//	@return *types.BuildTxResult
+//	@return error
+func (c *Chain) BuildTxSignWithSigner(ctx context.Context, s signer.Signer, txNoSign *eTypes.Transaction) (*types.BuildTxResult, error) {
+	if s == nil || txNoSign == nil {
+		return nil, errors.New("param is empty")
+	}
 
-	signedTx, err := eTypes.SignTx(txNoSign, eTypes.LatestSignerForChainID(c.ChainId), privateKey)
+	signedTx, err := s.SignTx(ctx, txNoSign, c.ChainId)
 	if err != nil {
 		return nil, err
 	}
@@ -185,14 +439,18 @@ func (c *Chain) BuildTxSign(privateKey *ecdsa.PrivateKey, txNoSign *eTypes.Trans
 }
 
 func (c *Chain) SendTx(signedTx *eTypes.Transaction) error {
+	return c.SendTxCtx(context.Background(), signedTx)
+}
+
+func (c *Chain) SendTxCtx(ctx context.Context, signedTx *eTypes.Transaction) error {
 	if signedTx == nil {
 		return errors.New("signed transaction can't be empty")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout)*time.Second)
+	ctx, cancel := c.WithTimeout(ctx)
 	defer cancel()
-	err := c.RemoteRpcClient.SendTransaction(ctx, signedTx)
+	err := c.Client().SendTransaction(ctx, signedTx)
 	if err != nil {
-		return err
+		return WrapRevertError(err, nil)
 	}
 	return nil
 }
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ScannedBlock
+//
+//	@Description: a block handled by the scanner
+type ScannedBlock struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// Checkpoint
+//
+//	@Description: where the scanner stopped, the recent blocks are kept to find the common ancestor of a reorg
+type Checkpoint struct {
+	Number uint64         `json:"number"` // the last handled block
+	Hash   string         `json:"hash"`
+	Recent []ScannedBlock `json:"recent"` // the oldest first, the last one is Number
+}
+
+func (c *Checkpoint) clone() *Checkpoint {
+	if c == nil {
+		return nil
+	}
+	result := *c
+	result.Recent = append([]ScannedBlock(nil), c.Recent...)
+	return &result
+}
+
+// CheckpointStore
+//
+//	@Description: persist the checkpoint of the scanner, eg: a file, redis or a database
+type CheckpointStore interface {
+	// Load nil if nothing is saved
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save called after every handled or rolled back block
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+// MemoryCheckpointStore
+//
+//	@Description: the checkpoint is lost when the process exits
+type MemoryCheckpointStore struct {
+	lock       sync.Mutex
+	checkpoint *Checkpoint
+}
+
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{}
+}
+
+func (m *MemoryCheckpointStore) Load(_ context.Context) (*Checkpoint, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.checkpoint.clone(), nil
+}
+
+func (m *MemoryCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.checkpoint = checkpoint.clone()
+	return nil
+}
+
+// FileCheckpointStore
+//
+//	@Description: the checkpoint is a json file, it is replaced by rename so a crash never leaves half a file
+type FileCheckpointStore struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		path: path,
+	}
+}
+
+func (f *FileCheckpointStore) Load(_ context.Context) (*Checkpoint, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	bytes, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &Checkpoint{}
+	if err = json.Unmarshal(bytes, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	bytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err = os.WriteFile(tmp, bytes, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
@@ -1,31 +1,46 @@
 package contract
 
 import (
-	"github.com/bitxx/evm-utils/util"
+	"context"
+	"github.com/bitxx/evm-utils/model/signer"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"math/big"
 )
 
 func Signer(privateKey string, chainId *big.Int) (bind.SignerFn, error) {
-	priData, err := util.HexDecodeString(privateKey)
+	s, err := signer.NewPrivateKeySigner(privateKey)
 	if err != nil {
 		return nil, err
 	}
+	return BindSigner(context.Background(), s, chainId), nil
+}
 
-	privateKeyECDSA, err := crypto.ToECDSA(priData)
-	if err != nil {
-		return nil, err
+// BindSigner
+//
+//	@Description: adapt a signer to the abigen binding
+//	@param ctx
+//	@param s
+//	@param chainId
+//	@return bind.SignerFn
+func BindSigner(ctx context.Context, s signer.Signer, chainId *big.Int) bind.SignerFn {
+	return func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return s.SignTx(ctx, tx, chainId)
 	}
+}
 
-	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
-		signer := types.LatestSignerForChainID(chainId)
-		signature, err := crypto.Sign(signer.Hash(tx).Bytes(), privateKeyECDSA)
-		if err != nil {
-			return nil, err
-		}
-		return tx.WithSignature(signer, signature)
-	}, nil
+// TransactOpts
+//
+//	@Description: the transact options of the abigen binding signed by s
+//	@param ctx
+//	@param s
+//	@param chainId
+//	@return *bind.TransactOpts
+func TransactOpts(ctx context.Context, s signer.Signer, chainId *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    s.Address(),
+		Signer:  BindSigner(ctx, s, chainId),
+		Context: ctx,
+	}
 }
@@ -0,0 +1,229 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/model"
+	"github.com/bitxx/evm-utils/model/contract/multicall3"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"sync"
+)
+
+// Call
+//
+//	@Description: one contract read of a multicall
+type Call struct {
+	Target       common.Address
+	CallData     []byte
+	AllowFailure bool     // if false, the whole multicall fails when this call fails
+	Abi          *abi.ABI // used to decode the result, can be nil
+	Method       string
+}
+
+// CallResult
+//
+//	@Description: the result of one call, Values is decoded if the call has an abi
+type CallResult struct {
+	Success    bool
+	ReturnData []byte
+	Values     []interface{}
+	Err        error
+}
+
+// NewCall
+//
+//	@Description: pack the call data of the method
+//	@param target
+//	@param contractAbi
+//	@param allowFailure
+//	@param method
+//	@param args
+//	@return Call
+//	@return error
+func NewCall(target common.Address, contractAbi *abi.ABI, allowFailure bool, method string, args ...interface{}) (Call, error) {
+	data, err := contractAbi.Pack(method, args...)
+	if err != nil {
+		return Call{}, err
+	}
+	return Call{
+		Target:       target,
+		CallData:     data,
+		AllowFailure: allowFailure,
+		Abi:          contractAbi,
+		Method:       method,
+	}, nil
+}
+
+// Multicall
+//
+//	@Description: batch many contract reads into one eth_call by multicall3 aggregate3,
+//	the calls are sent one by one if multicall3 is not deployed
+type Multicall struct {
+	caller    bind.ContractCaller
+	address   common.Address
+	lock      sync.Mutex
+	deployed  bool
+	deployKey string // the key of deployedMulticalls, empty if the deployment is only cached by this multicall
+}
+
+// deployedMulticalls the chain ids and addresses where multicall3 is found, shared by the multicalls of NewChainMulticall
+var deployedMulticalls sync.Map
+
+func NewMulticall(caller bind.ContractCaller) *Multicall {
+	return NewMulticallAt(caller, common.HexToAddress(config.Multicall3Address))
+}
+
+// NewMulticallAt
+//
+//	@Description: multicall3 is deployed at another address on some chains
+//	@param caller
+//	@param address
+//	@return *Multicall
+func NewMulticallAt(caller bind.ContractCaller, address common.Address) *Multicall {
+	return &Multicall{
+		caller:  caller,
+		address: address,
+	}
+}
+
+// NewChainMulticall
+//
+//	@Description: the multicall of the chain, the deployment of multicall3 is checked once per chain id,
+//	not once per multicall, so a new multicall for each query doesn't call eth_getCode again
+//	@param chain
+//	@return *Multicall
+func NewChainMulticall(chain *model.Chain) *Multicall {
+	return newSharedMulticall(chain.Client(), chain.ChainId)
+}
+
+func newSharedMulticall(caller bind.ContractCaller, chainId *big.Int) *Multicall {
+	m := NewMulticall(caller)
+	m.deployKey = chainId.String() + ":" + m.address.Hex()
+	return m
+}
+
+// Aggregate
+//
+//	@Description: run all calls, in batches of config.MulticallBatchSize
+//	@receiver m
+//	@param opts the block and the context of the calls, can be nil
+//	@param calls
+//	@return []CallResult in the order of calls
+//	@return error a call which doesn't allow failure failed, or the node failed
+func (m *Multicall) Aggregate(opts *bind.CallOpts, calls []Call) ([]CallResult, error) {
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	deployed, err := m.isDeployed(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !deployed {
+		return m.sequential(ctx, opts, calls)
+	}
+
+	link, err := multicall3.NewMulticall3Caller(m.address, m.caller)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]CallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += config.MulticallBatchSize {
+		end := start + config.MulticallBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		call3s := make([]multicall3.IMulticall3Call3, 0, end-start)
+		for _, call := range calls[start:end] {
+			call3s = append(call3s, multicall3.IMulticall3Call3{
+				Target:       call.Target,
+				AllowFailure: call.AllowFailure,
+				CallData:     call.CallData,
+			})
+		}
+		returns, err := link.Aggregate3(opts, call3s)
+		if err != nil {
+			return nil, err
+		}
+		if len(returns) != len(call3s) {
+			return nil, errors.New("the result count of multicall is wrong")
+		}
+		for i, ret := range returns {
+			results = append(results, decodeResult(calls[start+i], ret.Success, ret.ReturnData))
+		}
+	}
+	return results, nil
+}
+
+// isDeployed
+//
+//	@Description: only a deployed result is cached, the contract may be deployed later
+//	@receiver m
+//	@param ctx
+//	@param opts
+//	@return bool
+//	@return error
+func (m *Multicall) isDeployed(ctx context.Context, opts *bind.CallOpts) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.deployed {
+		return true, nil
+	}
+	if m.deployKey != "" {
+		if _, ok := deployedMulticalls.Load(m.deployKey); ok {
+			m.deployed = true
+			return true, nil
+		}
+	}
+	code, err := m.caller.CodeAt(ctx, m.address, opts.BlockNumber)
+	if err != nil {
+		return false, err
+	}
+	m.deployed = len(code) > 0
+	if m.deployed && m.deployKey != "" {
+		deployedMulticalls.Store(m.deployKey, struct{}{})
+	}
+	return m.deployed, nil
+}
+
+func (m *Multicall) sequential(ctx context.Context, opts *bind.CallOpts, calls []Call) ([]CallResult, error) {
+	results := make([]CallResult, len(calls))
+	for i, call := range calls {
+		target := call.Target
+		data, err := m.caller.CallContract(ctx, ethereum.CallMsg{From: opts.From, To: &target, Data: call.CallData}, opts.BlockNumber)
+		if err != nil {
+			err = model.WrapRevertError(err, call.Abi)
+			if ctx.Err() != nil || !call.AllowFailure {
+				return nil, err
+			}
+			results[i] = CallResult{Err: err}
+			continue
+		}
+		results[i] = decodeResult(call, true, data)
+	}
+	return results, nil
+}
+
+func decodeResult(call Call, success bool, data []byte) CallResult {
+	result := CallResult{
+		Success:    success,
+		ReturnData: data,
+	}
+	if !success {
+		result.Err = model.DecodeRevert(data, call.Abi)
+		return result
+	}
+	if call.Abi != nil && call.Method != "" {
+		result.Values, result.Err = call.Abi.Unpack(call.Method, data)
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/model/contract/multicall3"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+var (
+	testToken    = common.HexToAddress("0x0000000000000000000000000000000000000010")
+	testNotToken = common.HexToAddress("0x0000000000000000000000000000000000000020")
+)
+
+// fakeCaller
+//
+//	@Description: testToken returns the last byte of the owner as the balance, the other addresses revert
+type fakeCaller struct {
+	multicall bool
+	calls     int
+	codes     int
+}
+
+func (f *fakeCaller) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	f.codes++
+	if f.multicall {
+		return []byte{0x60}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls++
+	if *msg.To == testToken {
+		return balanceOf(msg.Data)
+	}
+	if !f.multicall {
+		return nil, errors.New("execution reverted")
+	}
+
+	multicallAbi, _ := multicall3.Multicall3MetaData.GetAbi()
+	args, err := multicallAbi.Methods["aggregate3"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := args[0].([]struct {
+		Target       common.Address `json:"target"`
+		AllowFailure bool           `json:"allowFailure"`
+		CallData     []byte         `json:"callData"`
+	})
+	results := make([]multicall3.IMulticall3Result, len(calls))
+	for i, call := range calls {
+		if call.Target != testToken {
+			if !call.AllowFailure {
+				return nil, errors.New("execution reverted")
+			}
+			continue
+		}
+		data, err := balanceOf(call.CallData)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = multicall3.IMulticall3Result{Success: true, ReturnData: data}
+	}
+	return multicallAbi.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+func balanceOf(data []byte) ([]byte, error) {
+	erc20Abi, _ := erc20.ERC20MetaData.GetAbi()
+	args, err := erc20Abi.Methods["balanceOf"].Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	owner := args[0].(common.Address)
+	return erc20Abi.Methods["balanceOf"].Outputs.Pack(new(big.Int).SetBytes(owner.Bytes()[19:]))
+}
+
+func testCalls(t *testing.T) []Call {
+	erc20Abi, err := erc20.ERC20MetaData.GetAbi()
+	require.Nil(t, err)
+	var calls []Call
+	for _, token := range []common.Address{testToken, testNotToken} {
+		call, err := NewCall(token, erc20Abi, true, "balanceOf", common.HexToAddress("0x0000000000000000000000000000000000000007"))
+		require.Nil(t, err)
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+func TestMulticallAggregate(t *testing.T) {
+	for _, deployed := range []bool{true, false} {
+		caller := &fakeCaller{multicall: deployed}
+		results, err := NewMulticall(caller).Aggregate(nil, testCalls(t))
+		require.Nil(t, err)
+		require.Len(t, results, 2)
+		require.Nil(t, results[0].Err)
+		require.Equal(t, big.NewInt(7), results[0].Values[0])
+		require.NotNil(t, results[1].Err)
+		if deployed {
+			require.Equal(t, 1, caller.calls)
+		} else {
+			require.Equal(t, 2, caller.calls)
+		}
+	}
+}
+
+func TestMulticallNotAllowFailure(t *testing.T) {
+	calls := testCalls(t)
+	calls[1].AllowFailure = false
+	for _, deployed := range []bool{true, false} {
+		_, err := NewMulticall(&fakeCaller{multicall: deployed}).Aggregate(&bind.CallOpts{}, calls)
+		require.NotNil(t, err)
+	}
+}
+
+func TestChainMulticallDeployed(t *testing.T) {
+	// a new multicall of the same chain id doesn't check the deployment again
+	caller := &fakeCaller{multicall: true}
+	for i := 0; i < 3; i++ {
+		_, err := newSharedMulticall(caller, big.NewInt(1001)).Aggregate(nil, testCalls(t))
+		require.Nil(t, err)
+	}
+	require.Equal(t, 1, caller.codes)
+
+	// it may be deployed later
+	caller = &fakeCaller{}
+	for i := 0; i < 2; i++ {
+		_, err := newSharedMulticall(caller, big.NewInt(1002)).Aggregate(nil, testCalls(t))
+		require.Nil(t, err)
+	}
+	require.Equal(t, 2, caller.codes)
+}
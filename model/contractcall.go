@@ -0,0 +1,107 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Contract
+//
+//	@Description: read and write any contract by its abi, no abigen binding is needed
+type Contract struct {
+	chain           *Chain
+	contractAddress string
+	contractAbi     *abi.ABI
+}
+
+func NewContract(chain *Chain, contractAddress string, contractAbi *abi.ABI) *Contract {
+	return &Contract{
+		chain:           chain,
+		contractAddress: contractAddress,
+		contractAbi:     contractAbi,
+	}
+}
+
+func (c *Contract) Call(block BlockTag, method string, args ...interface{}) ([]interface{}, error) {
+	return c.CallCtx(context.Background(), block, method, args...)
+}
+
+// CallCtx
+//
+//	@Description: eth_call the method at the block, the outputs are decoded by the abi
+//	@receiver c
+//	@param ctx
+//	@param block latest, pending, safe, finalized, or a block number, empty is latest
+//	@param method
+//	@param args
+//	@return []interface{} the outputs in order
+//	@return error a revert is *RevertError
+func (c *Contract) CallCtx(ctx context.Context, block BlockTag, method string, args ...interface{}) ([]interface{}, error) {
+	if c.chain == nil {
+		return nil, errors.New("the chain node is empty")
+	}
+	if c.contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	if !util.IsValidAddress(c.contractAddress) {
+		return nil, errors.New("contract address format is error")
+	}
+	blockNumber, err := block.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.contractAbi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := c.chain.WithTimeout(ctx)
+	defer cancel()
+	to := common.HexToAddress(c.contractAddress)
+	result, err := c.chain.Client().CallContract(callCtx, ethereum.CallMsg{To: &to, Data: data}, blockNumber)
+	if err != nil {
+		return nil, WrapRevertError(err, c.contractAbi)
+	}
+	if len(result) == 0 && len(c.contractAbi.Methods[method].Outputs) > 0 {
+		return nil, errors.New("the contract returns nothing, it may not be deployed at the block")
+	}
+	return c.contractAbi.Unpack(method, result)
+}
+
+func (c *Contract) Send(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, method string, args ...interface{}) (hash string, err error) {
+	return c.SendCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, method, args...)
+}
+
+// SendCtx
+//
+//	@Description: send the tx of the method, it is built, signed and sent like Token.TransferWithSigner,
+//	the empty nonce, fees and gas limit are filled by the nonce manager, the fee oracle and the estimation
+//	@receiver c
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value the wei sent to a payable method
+//	@param method
+//	@param args
+//	@return hash
+//	@return err
+func (c *Contract) SendCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, method string, args ...interface{}) (hash string, err error) {
+	if c.chain == nil {
+		return "", errors.New("the chain node is empty")
+	}
+	if value == "" {
+		value = "0"
+	}
+	if _, err = parseUint(value); err != nil {
+		return "", err
+	}
+	return NewToken(c.chain).sendContractCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, c.contractAddress, c.contractAbi, method, args...)
+}
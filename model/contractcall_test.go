@@ -0,0 +1,60 @@
+package model
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testContractAbi = `[{"type":"function","name":"getValue","stateMutability":"view",
+	"inputs":[{"name":"key","type":"uint256"}],
+	"outputs":[{"name":"value","type":"uint256"},{"name":"owner","type":"address"}]}]`
+
+// blockCallStub
+//
+//	@Description: eth_call records the block of the call
+type blockCallStub struct {
+	lock   sync.Mutex
+	blocks []string
+}
+
+func (b *blockCallStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (b *blockCallStub) Call(_ map[string]interface{}, block string) (hexutil.Bytes, error) {
+	b.lock.Lock()
+	b.blocks = append(b.blocks, block)
+	b.lock.Unlock()
+	parsed, err := abi.JSON(strings.NewReader(testContractAbi))
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Methods["getValue"].Outputs.Pack(big.NewInt(42), common.HexToAddress(testNonceAddress))
+}
+
+func TestContractCall(t *testing.T) {
+	stub := &blockCallStub{}
+	parsed, err := abi.JSON(strings.NewReader(testContractAbi))
+	require.Nil(t, err)
+	contract := NewContract(newStubChain(t, stub), testNftAddress, &parsed)
+
+	for _, block := range []BlockTag{"", BlockTagPending, BlockTagSafe, BlockTagFinalized, BlockTagNumber(100), "100"} {
+		values, err := contract.CallCtx(context.Background(), block, "getValue", big.NewInt(1))
+		require.Nil(t, err)
+		require.Equal(t, "42", values[0].(*big.Int).String())
+		require.Equal(t, common.HexToAddress(testNonceAddress), values[1])
+	}
+	require.Equal(t, []string{"latest", "pending", "safe", "finalized", "0x64", "0x64"}, stub.blocks)
+
+	_, err = contract.CallCtx(context.Background(), "unknown", "getValue", big.NewInt(1))
+	require.NotNil(t, err)
+	_, err = contract.CallCtx(context.Background(), "", "getValue")
+	require.NotNil(t, err)
+}
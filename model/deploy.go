@@ -0,0 +1,139 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeployCtx
+//
+//	@Description: send the contract creation tx, the tx has no receiver, it is sent like TransferWithSigner.
+//	the contract address is computed by the sender and the nonce of the signed tx
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit empty is estimated
+//	@param maxPriorityFeePerGas
+//	@param value the wei sent to a payable constructor
+//	@param bytecode hex, the creation bytecode without constructor args
+//	@param contractAbi encode the constructor args, can be nil if the constructor has no args
+//	@param args
+//	@return address the contract address once the tx is mined
+//	@return hash
+//	@return err
+func (t *Token) DeployCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, bytecode string, contractAbi *abi.ABI, args ...interface{}) (address, hash string, err error) {
+	if t.chain == nil {
+		return "", "", errors.New("the chain node is empty")
+	}
+	if s == nil {
+		return "", "", errors.New("param is error")
+	}
+	if value == "" {
+		value = "0"
+	}
+	if _, err = parseUint(value); err != nil {
+		return "", "", err
+	}
+	data, err := deployData(bytecode, contractAbi, args...)
+	if err != nil {
+		return "", "", err
+	}
+	if gasLimit == "" {
+		// no receiver, estimate the creation
+		gasLimit, err = t.EstimateGasLimitCtx(ctx, s.Address().Hex(), "", gasPrice, value, data)
+		if err != nil {
+			var revertErr *RevertError
+			if contractAbi != nil && errors.As(err, &revertErr) && len(revertErr.Data) > 0 {
+				decoded := DecodeRevert(revertErr.Data, contractAbi)
+				decoded.Err = revertErr.Err
+				return "", "", decoded
+			}
+			return "", "", err
+		}
+	}
+	result, err := t.transferWithSigner(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, "", hexutil.Encode(data))
+	if result == nil {
+		return "", "", err
+	}
+	address = crypto.CreateAddress(s.Address(), result.SignedTx.Nonce()).Hex()
+	return address, result.TxHex, err
+}
+
+// WaitDeployedCtx
+//
+//	@Description: wait for the receipt of the creation tx, the contract must be created at the address with code
+//	@receiver t
+//	@param ctx the waiting is only stopped by ctx
+//	@param address the address returned by DeployCtx
+//	@param hash
+//	@param confirmations
+//	@return *Transaction
+//	@return error the tx is reverted, dropped or replaced, or no code is at the address
+func (t *Token) WaitDeployedCtx(ctx context.Context, address, hash string, confirmations uint64) (*Transaction, error) {
+	if t.chain == nil {
+		return nil, errors.New("the chain node is empty")
+	}
+	if !util.IsValidAddress(address) {
+		return nil, errors.New("contract address format is error")
+	}
+	tx, err := NewTransaction(t.chain).WaitForReceipt(ctx, hash, confirmations)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != TxStatusSuccess {
+		return tx, fmt.Errorf("the deployment tx is %s", tx.Status)
+	}
+	if !common.IsHexAddress(tx.ContractAddress) || common.HexToAddress(tx.ContractAddress) != common.HexToAddress(address) {
+		return tx, fmt.Errorf("the contract is created at %s, not %s", tx.ContractAddress, address)
+	}
+
+	callCtx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+	code, err := t.chain.Client().CodeAt(callCtx, common.HexToAddress(address), nil)
+	if err != nil {
+		return tx, err
+	}
+	if len(code) == 0 {
+		return tx, errors.New("no code at the contract address")
+	}
+	return tx, nil
+}
+
+// deployData
+//
+//	@Description: the creation bytecode followed by the abi encoded constructor args
+//	@param bytecode
+//	@param contractAbi
+//	@param args
+//	@return []byte
+//	@return error
+func deployData(bytecode string, contractAbi *abi.ABI, args ...interface{}) ([]byte, error) {
+	code, err := util.HexDecodeString(bytecode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bytecode: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, errors.New("bytecode is empty")
+	}
+	if contractAbi == nil {
+		if len(args) > 0 {
+			return nil, errors.New("abi is empty, the constructor args can't be encoded")
+		}
+		return code, nil
+	}
+	// the empty method is the constructor
+	input, err := contractAbi.Pack("", args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(code, input...), nil
+}
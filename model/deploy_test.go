@@ -0,0 +1,73 @@
+package model
+
+import (
+	"context"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const testDeployAbi = `[{"type":"constructor","stateMutability":"nonpayable","inputs":[{"name":"supply","type":"uint256"}]}]`
+
+// deployStub
+//
+//	@Description: records the estimation and the raw tx of the deployment
+type deployStub struct {
+	ethStub
+	estimateTo interface{}
+	sent       *types.Transaction
+}
+
+func (d *deployStub) EstimateGas(args map[string]interface{}) (hexutil.Uint64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.estimateTo = args["to"]
+	return hexutil.Uint64(100000), nil
+}
+
+func (d *deployStub) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.sent = tx
+	return tx.Hash(), nil
+}
+
+func TestDeploy(t *testing.T) {
+	stub := &deployStub{ethStub: ethStub{nonce: 7}}
+	token := NewToken(newStubChain(t, stub))
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	parsed, err := abi.JSON(strings.NewReader(testDeployAbi))
+	require.Nil(t, err)
+
+	address, hash, err := token.DeployCtx(context.Background(), s, "", "1000000000", "", "", "", "0x6080", &parsed, big.NewInt(1000))
+	require.Nil(t, err)
+	require.Nil(t, stub.estimateTo)
+	require.NotNil(t, stub.sent)
+	require.Nil(t, stub.sent.To())
+	require.Equal(t, uint64(7), stub.sent.Nonce())
+	require.True(t, stub.sent.Gas() > 100000)
+	require.Equal(t, stub.sent.Hash().Hex(), hash)
+	require.Equal(t, crypto.CreateAddress(s.Address(), 7).Hex(), address)
+
+	input, err := parsed.Pack("", big.NewInt(1000))
+	require.Nil(t, err)
+	require.Equal(t, append([]byte{0x60, 0x80}, input...), stub.sent.Data())
+
+	// the constructor args need the abi
+	_, _, err = token.DeployCtx(context.Background(), s, "", "1000000000", "", "", "", "0x6080", nil, big.NewInt(1000))
+	require.NotNil(t, err)
+	_, _, err = token.DeployCtx(context.Background(), s, "", "1000000000", "", "", "", "", &parsed, big.NewInt(1000))
+	require.NotNil(t, err)
+}
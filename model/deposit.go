@@ -0,0 +1,309 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sync"
+)
+
+type DepositKind string
+
+const (
+	DepositNative DepositKind = "native" // the value of a tx, the internal transfers of contracts are not included
+	DepositErc20  DepositKind = "erc20"  // the Transfer log of an erc20 token
+)
+
+// Deposit
+//
+//	@Description: a transfer to a watched address
+type Deposit struct {
+	Id            string // tx hash for native, tx hash:token:to:amount:ordinal for erc20, it doesn't change if a reorg moves the tx
+	Kind          DepositKind
+	Token         string // the erc20 contract, empty for native
+	From          string
+	To            string
+	Amount        decimal.Decimal // wei, or the smallest unit of the token
+	TxHash        string
+	LogIndex      uint
+	BlockNumber   uint64
+	BlockHash     string
+	Time          uint64
+	Confirmations uint64       // when it is emitted, the head has 1, at least DepositWatcherOptions.Confirmations
+	Transaction   *Transaction // native only
+}
+
+// DepositWatcherOptions
+//
+//	@Description: the zero values of the stores are the memory stores
+type DepositWatcherOptions struct {
+	Addresses     []string
+	Tokens        []string      // the erc20 contracts, empty is any token
+	Kinds         []DepositKind // empty is native and erc20
+	StartBlock    uint64
+	Confirmations uint64 // the deposit is emitted once the block has enough confirmations, the head has 1, 0 is the same as 1
+	Store         CheckpointStore
+	Seen          DepositStore
+
+	// OnDeposit nil is sending the deposits to the channel of Deposits
+	OnDeposit func(ctx context.Context, deposit *Deposit) error
+	// OnRollback the blocks after number are reorged out, only happens if the reorg is deeper than the confirmations
+	OnRollback func(ctx context.Context, number uint64) error
+}
+
+// DepositWatcher
+//
+//	@Description: emit the native and erc20 transfers to the watched addresses, it is built on the Scanner
+type DepositWatcher struct {
+	chain     *Chain
+	options   DepositWatcherOptions
+	scanner   *Scanner
+	deposits  chan *Deposit
+	lock      sync.RWMutex
+	addresses map[common.Address]struct{}
+	head      uint64
+	time      uint64         // the time of the block being scanned
+	ordinals  map[string]int // the erc20 transfers of the block being scanned with the same tx, token, to and amount
+}
+
+func NewDepositWatcher(chain *Chain, options DepositWatcherOptions) (*DepositWatcher, error) {
+	if chain == nil {
+		return nil, errors.New("the chain node is empty")
+	}
+	if options.Seen == nil {
+		options.Seen = NewMemoryDepositStore()
+	}
+	w := &DepositWatcher{
+		chain:    chain,
+		options:  options,
+		deposits: make(chan *Deposit),
+	}
+	if err := w.SetAddresses(options.Addresses); err != nil {
+		return nil, err
+	}
+	for _, token := range options.Tokens {
+		if !util.IsValidAddress(token) {
+			return nil, errors.New("token address format is error")
+		}
+	}
+
+	scannerOptions := ScannerOptions{
+		StartBlock:    options.StartBlock,
+		Confirmations: options.Confirmations,
+		Store:         options.Store,
+		OnBlock:       w.onBlock,
+		OnRollback:    options.OnRollback,
+	}
+	if w.watches(DepositNative) {
+		scannerOptions.TransactionFilter = w.filterTx
+		scannerOptions.OnTransaction = w.onTransaction
+	}
+	if w.watches(DepositErc20) {
+		erc20Abi, err := erc20.ERC20MetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		scannerOptions.LogAddresses = options.Tokens
+		scannerOptions.LogTopics = [][]string{{erc20Abi.Events["Transfer"].ID.Hex()}}
+		scannerOptions.Abis = []*abi.ABI{erc20Abi}
+		scannerOptions.OnLog = w.onLog
+	}
+	w.scanner = NewScanner(chain, scannerOptions)
+	return w, nil
+}
+
+// SetAddresses
+//
+//	@Description: replace the watched addresses, it works from the next block
+//	@receiver w
+//	@param addresses
+//	@return error
+func (w *DepositWatcher) SetAddresses(addresses []string) error {
+	watched := make(map[common.Address]struct{}, len(addresses))
+	for _, address := range addresses {
+		if !util.IsValidAddress(address) {
+			return fmt.Errorf("address format is error: %s", address)
+		}
+		watched[common.HexToAddress(address)] = struct{}{}
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.addresses = watched
+	return nil
+}
+
+func (w *DepositWatcher) AddAddresses(addresses ...string) error {
+	for _, address := range addresses {
+		if !util.IsValidAddress(address) {
+			return fmt.Errorf("address format is error: %s", address)
+		}
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, address := range addresses {
+		w.addresses[common.HexToAddress(address)] = struct{}{}
+	}
+	return nil
+}
+
+func (w *DepositWatcher) RemoveAddresses(addresses ...string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, address := range addresses {
+		delete(w.addresses, common.HexToAddress(address))
+	}
+}
+
+// Deposits
+//
+//	@Description: the deposits if OnDeposit is nil. it is unbuffered, a deposit is remembered once it is received,
+//	so the deposits not received before a crash are emitted again. it is never closed, Run can be called again
+//	@receiver w
+//	@return <-chan *Deposit
+func (w *DepositWatcher) Deposits() <-chan *Deposit {
+	return w.deposits
+}
+
+// Run
+//
+//	@Description: follow the head until ctx is done or OnDeposit fails, the failed block is scanned again next time
+//	@receiver w
+//	@param ctx
+//	@return error
+func (w *DepositWatcher) Run(ctx context.Context) error {
+	return w.scanner.Run(ctx)
+}
+
+// Scan
+//
+//	@Description: scan from the checkpoint to the confirmed head once
+//	@receiver w
+//	@param ctx
+//	@return error
+func (w *DepositWatcher) Scan(ctx context.Context) error {
+	return w.scanner.Scan(ctx)
+}
+
+// Checkpoint
+//
+//	@Description: the last scanned block
+//	@receiver w
+//	@return *Checkpoint
+func (w *DepositWatcher) Checkpoint() *Checkpoint {
+	return w.scanner.Checkpoint()
+}
+
+func (w *DepositWatcher) onBlock(ctx context.Context, block *types.Block) error {
+	w.time = block.Time()
+	w.ordinals = map[string]int{}
+	// the confirmations of the deposits in the block, by the head the scan started with
+	w.head = w.scanner.head
+	return nil
+}
+
+func (w *DepositWatcher) filterTx(tx *types.Transaction) bool {
+	return tx.To() != nil && tx.Value().Sign() > 0 && w.watched(*tx.To())
+}
+
+func (w *DepositWatcher) onTransaction(ctx context.Context, tx *Transaction) error {
+	if tx.Status != TxStatusSuccess {
+		return nil
+	}
+	return w.emit(ctx, &Deposit{
+		Id:          tx.Hash,
+		Kind:        DepositNative,
+		From:        tx.From,
+		To:          common.HexToAddress(tx.To).Hex(),
+		Amount:      tx.Value,
+		TxHash:      tx.Hash,
+		BlockNumber: tx.BlockNumber,
+		BlockHash:   tx.BlockHash,
+		Time:        tx.Time,
+		Transaction: tx,
+	})
+}
+
+func (w *DepositWatcher) onLog(ctx context.Context, log EventLog) error {
+	// the erc721 Transfer has the same id, it is not decoded by the erc20 abi
+	if log.Event != "Transfer" || log.Removed {
+		return nil
+	}
+	from, _ := log.Fields["from"].(common.Address)
+	to, _ := log.Fields["to"].(common.Address)
+	value, _ := log.Fields["value"].(*big.Int)
+	if value == nil || value.Sign() == 0 || !w.watched(to) {
+		return nil
+	}
+	// the log index is the position in the block, it changes if the tx is included in another block
+	key := fmt.Sprintf("%s:%s:%s:%s", log.TxHash, log.Address, to.Hex(), value)
+	ordinal := w.ordinals[key]
+	w.ordinals[key]++
+	return w.emit(ctx, &Deposit{
+		Id:          fmt.Sprintf("%s:%d", key, ordinal),
+		Kind:        DepositErc20,
+		Token:       log.Address,
+		From:        from.Hex(),
+		To:          to.Hex(),
+		Amount:      decimal.NewFromBigInt(value, 0),
+		TxHash:      log.TxHash,
+		LogIndex:    log.LogIndex,
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
+		Time:        w.time,
+	})
+}
+
+// emit
+//
+//	@Description: hand over the deposit once, it is remembered after OnDeposit succeeds or the receiver of the channel takes it
+//	@receiver w
+//	@param ctx
+//	@param deposit
+//	@return error
+func (w *DepositWatcher) emit(ctx context.Context, deposit *Deposit) error {
+	seen, err := w.options.Seen.Has(ctx, deposit.Id)
+	if err != nil || seen {
+		return err
+	}
+	if w.head >= deposit.BlockNumber {
+		deposit.Confirmations = w.head - deposit.BlockNumber + 1
+	}
+	if w.options.OnDeposit != nil {
+		if err = w.options.OnDeposit(ctx, deposit); err != nil {
+			return err
+		}
+	} else {
+		select {
+		case w.deposits <- deposit:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return w.options.Seen.Add(ctx, deposit.Id)
+}
+
+func (w *DepositWatcher) watched(address common.Address) bool {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	_, ok := w.addresses[address]
+	return ok
+}
+
+func (w *DepositWatcher) watches(kind DepositKind) bool {
+	if len(w.options.Kinds) == 0 {
+		return true
+	}
+	for _, k := range w.options.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testDepositToken = "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"
+
+// depositStub
+//
+//	@Description: block 2 has a native transfer to the watched address, block 3 has an erc20 Transfer log to it
+type depositStub struct {
+	*chainStub
+	txs      map[uint64]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	logBlock int  // the block of the erc20 Transfer log
+	logIndex uint // the index of the log in the block
+	logTx    string
+}
+
+func newDepositStub(t *testing.T) *depositStub {
+	key, err := crypto.HexToECDSA("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	tx, err := types.SignNewTx(key, types.NewLondonSigner(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(5000),
+	})
+	require.Nil(t, err)
+
+	stub := &depositStub{
+		chainStub: &chainStub{},
+		txs:       map[uint64]*types.Transaction{2: tx},
+		receipts:  map[common.Hash]*types.Receipt{},
+		logBlock:  3,
+		logIndex:  4,
+		logTx:     "0xaa",
+	}
+	stub.chainStub.txRoot = func(number int) common.Hash {
+		if stub.txs[uint64(number)] != nil {
+			return common.HexToHash("0x01")
+		}
+		return types.EmptyTxsHash
+	}
+	stub.fork(0, 6, 0)
+	stub.receipts[tx.Hash()] = &types.Receipt{
+		Type:        tx.Type(),
+		Status:      types.ReceiptStatusSuccessful,
+		Logs:        []*types.Log{},
+		TxHash:      tx.Hash(),
+		GasUsed:     21000,
+		BlockHash:   common.HexToHash(stub.hash(2)),
+		BlockNumber: big.NewInt(2),
+	}
+	return stub
+}
+
+func (d *depositStub) GetBlockByNumber(number string, full bool) (map[string]interface{}, error) {
+	result, err := d.chainStub.GetBlockByNumber(number, full)
+	if err != nil || result == nil {
+		return result, err
+	}
+	n, _ := hexutil.DecodeUint64(number)
+	if tx := d.txs[n]; tx != nil {
+		bytes, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		fields := map[string]interface{}{}
+		if err = json.Unmarshal(bytes, &fields); err != nil {
+			return nil, err
+		}
+		fields["blockNumber"] = number
+		result["transactions"] = []interface{}{fields}
+	}
+	return result, nil
+}
+
+func (d *depositStub) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	return d.receipts[hash], nil
+}
+
+func (d *depositStub) GetLogs(args map[string]interface{}) ([]types.Log, error) {
+	blockHash := common.HexToHash(args["blockHash"].(string))
+	if blockHash != common.HexToHash(d.hash(d.logBlock)) {
+		return []types.Log{}, nil
+	}
+	erc20Abi, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	value, err := erc20Abi.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(700))
+	if err != nil {
+		return nil, err
+	}
+	log := types.Log{
+		Address:     common.HexToAddress(testDepositToken),
+		BlockNumber: uint64(d.logBlock),
+		BlockHash:   blockHash,
+		TxHash:      common.HexToHash(d.logTx),
+		Index:       d.logIndex,
+		Topics: []common.Hash{
+			erc20Abi.Events["Transfer"].ID,
+			common.BytesToHash(common.HexToAddress(testNftAddress).Bytes()),
+			common.BytesToHash(common.HexToAddress(testNonceAddress).Bytes()),
+		},
+		Data: value,
+	}
+	// the transfer to an address not watched
+	other := log
+	other.Index = d.logIndex + 1
+	other.Topics = []common.Hash{log.Topics[0], log.Topics[2], log.Topics[1]}
+	return []types.Log{log, other}, nil
+}
+
+func TestDepositWatcher(t *testing.T) {
+	stub := newDepositStub(t)
+	chain := newStubChain(t, stub)
+	seen := NewFileDepositStore(filepath.Join(t.TempDir(), "deposits"))
+	var deposits []*Deposit
+	options := DepositWatcherOptions{
+		Addresses:     []string{testNonceAddress},
+		StartBlock:    1,
+		Confirmations: 1,
+		Seen:          seen,
+		OnDeposit: func(ctx context.Context, deposit *Deposit) error {
+			deposits = append(deposits, deposit)
+			return nil
+		},
+	}
+	watcher, err := NewDepositWatcher(chain, options)
+	require.Nil(t, err)
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 2)
+
+	require.Equal(t, DepositNative, deposits[0].Kind)
+	require.Equal(t, "5000", deposits[0].Amount.String())
+	require.Equal(t, common.HexToAddress(testNonceAddress).Hex(), deposits[0].To)
+	require.Equal(t, uint64(2), deposits[0].BlockNumber)
+	require.Equal(t, uint64(4), deposits[0].Confirmations)
+	require.Equal(t, stub.txs[2].Hash().Hex(), deposits[0].Id)
+
+	require.Equal(t, DepositErc20, deposits[1].Kind)
+	require.Equal(t, "700", deposits[1].Amount.String())
+	require.Equal(t, common.HexToAddress(testDepositToken).Hex(), deposits[1].Token)
+	require.Equal(t, common.HexToAddress(testNftAddress).Hex(), deposits[1].From)
+	require.Equal(t, uint64(3), deposits[1].Confirmations)
+	require.Equal(t, common.HexToHash("0xaa").Hex()+":"+deposits[1].Token+":"+deposits[1].To+":700:0", deposits[1].Id)
+
+	// no checkpoint is saved, the blocks are scanned again after the restart, but the deposits are not emitted again
+	deposits = nil
+	watcher, err = NewDepositWatcher(chain, options)
+	require.Nil(t, err)
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 0)
+
+	// the hot reloaded addresses, only native
+	deposits = nil
+	options.Kinds = []DepositKind{DepositNative}
+	options.Seen = NewMemoryDepositStore()
+	watcher, err = NewDepositWatcher(chain, options)
+	require.Nil(t, err)
+	watcher.RemoveAddresses(testNonceAddress)
+	require.Nil(t, watcher.AddAddresses(testNftAddress))
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 0)
+	require.NotNil(t, watcher.SetAddresses([]string{"0x123"}))
+}
+
+func TestDepositWatcherChannel(t *testing.T) {
+	stub := newDepositStub(t)
+	watcher, err := NewDepositWatcher(newStubChain(t, stub), DepositWatcherOptions{
+		Addresses: []string{testNonceAddress},
+		Kinds:     []DepositKind{DepositErc20},
+		Tokens:    []string{testDepositToken},
+	})
+	require.Nil(t, err)
+	done := make(chan error)
+	go func() {
+		done <- watcher.Scan(context.Background())
+	}()
+	deposit := <-watcher.Deposits()
+	require.Equal(t, "700", deposit.Amount.String())
+	require.Nil(t, <-done)
+	require.Equal(t, uint64(5), watcher.Checkpoint().Number)
+}
+
+func TestDepositWatcherNotReceived(t *testing.T) {
+	stub := newDepositStub(t)
+	seen := NewMemoryDepositStore()
+	options := DepositWatcherOptions{
+		Addresses: []string{testNonceAddress},
+		Kinds:     []DepositKind{DepositErc20},
+		Seen:      seen,
+	}
+	watcher, err := NewDepositWatcher(newStubChain(t, stub), options)
+	require.Nil(t, err)
+
+	// nobody receives the deposit before the crash, it is not remembered
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.True(t, errors.Is(watcher.Scan(ctx), context.DeadlineExceeded))
+	watcher, err = NewDepositWatcher(newStubChain(t, stub), options)
+	require.Nil(t, err)
+	go func() {
+		_ = watcher.Scan(context.Background())
+	}()
+	deposit := <-watcher.Deposits()
+	require.Equal(t, "700", deposit.Amount.String())
+}
+
+func TestDepositWatcherRunAgain(t *testing.T) {
+	stub := newDepositStub(t)
+	watcher, err := NewDepositWatcher(newStubChain(t, stub), DepositWatcherOptions{
+		Addresses: []string{testNonceAddress},
+		Kinds:     []DepositKind{DepositErc20},
+	})
+	require.Nil(t, err)
+	received := make(chan *Deposit, 2)
+	go func() {
+		for deposit := range watcher.Deposits() {
+			received <- deposit
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		require.True(t, errors.Is(watcher.Run(ctx), context.DeadlineExceeded))
+		cancel()
+	}
+	require.Equal(t, "700", (<-received).Amount.String())
+
+	// a new erc20 transfer after Run returned
+	stub.logBlock = 6
+	stub.logTx = "0xbb"
+	stub.fork(6, 7, 0)
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Equal(t, uint64(6), (<-received).BlockNumber)
+}
+
+func TestDepositWatcherReceiptOfOtherBlock(t *testing.T) {
+	stub := newDepositStub(t)
+	var deposits []*Deposit
+	watcher, err := NewDepositWatcher(newStubChain(t, stub), DepositWatcherOptions{
+		Addresses: []string{testNonceAddress},
+		Kinds:     []DepositKind{DepositNative},
+		OnDeposit: func(ctx context.Context, deposit *Deposit) error {
+			deposits = append(deposits, deposit)
+			return nil
+		},
+	})
+	require.Nil(t, err)
+
+	// block 2 is reorged after it is fetched, the receipt is of the new block
+	receipt := stub.receipts[stub.txs[2].Hash()]
+	receipt.BlockHash = common.HexToHash("0x02")
+	require.NotNil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 0)
+	require.Equal(t, uint64(1), watcher.Checkpoint().Number)
+
+	receipt.BlockHash = common.HexToHash(stub.hash(2))
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 1)
+}
+
+func TestDepositWatcherReorg(t *testing.T) {
+	stub := newDepositStub(t)
+	var deposits []*Deposit
+	watcher, err := NewDepositWatcher(newStubChain(t, stub), DepositWatcherOptions{
+		Addresses: []string{testNonceAddress},
+		Kinds:     []DepositKind{DepositErc20},
+		OnDeposit: func(ctx context.Context, deposit *Deposit) error {
+			deposits = append(deposits, deposit)
+			return nil
+		},
+	})
+	require.Nil(t, err)
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 1)
+	require.Equal(t, uint(4), deposits[0].LogIndex)
+
+	// the tx is included again in another block at another log index, it is not emitted twice
+	stub.logBlock = 4
+	stub.logIndex = 9
+	stub.fork(3, 7, 1)
+	require.Nil(t, watcher.Scan(context.Background()))
+	require.Len(t, deposits, 1)
+	require.Equal(t, uint64(6), watcher.Checkpoint().Number)
+}
+
+func TestDepositWatcherConfirmations(t *testing.T) {
+	stub := newDepositStub(t)
+	chain := newStubChain(t, stub)
+
+	// the head is 5, the erc20 deposit of block 3 has 3 confirmations, the native one of block 2 has 4
+	for confirmations, count := range map[uint64]int{3: 2, 4: 1} {
+		var deposits []*Deposit
+		watcher, err := NewDepositWatcher(chain, DepositWatcherOptions{
+			Addresses:     []string{testNonceAddress},
+			StartBlock:    1,
+			Confirmations: confirmations,
+			OnDeposit: func(ctx context.Context, deposit *Deposit) error {
+				deposits = append(deposits, deposit)
+				return nil
+			},
+		})
+		require.Nil(t, err)
+		require.Nil(t, watcher.Scan(context.Background()))
+		require.Len(t, deposits, count)
+		require.Equal(t, confirmations, deposits[count-1].Confirmations)
+	}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DepositStore
+//
+//	@Description: remember the handled deposits, a deposit is never emitted twice, eg: after a restart
+type DepositStore interface {
+	Has(ctx context.Context, id string) (bool, error)
+	Add(ctx context.Context, id string) error
+}
+
+// MemoryDepositStore
+//
+//	@Description: the deposits are forgotten when the process exits
+type MemoryDepositStore struct {
+	lock sync.Mutex
+	ids  map[string]struct{}
+}
+
+func NewMemoryDepositStore() *MemoryDepositStore {
+	return &MemoryDepositStore{
+		ids: make(map[string]struct{}),
+	}
+}
+
+func (m *MemoryDepositStore) Has(_ context.Context, id string) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, ok := m.ids[id]
+	return ok, nil
+}
+
+func (m *MemoryDepositStore) Add(_ context.Context, id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ids[id] = struct{}{}
+	return nil
+}
+
+// FileDepositStore
+//
+//	@Description: one id per line, the file is read once and appended after that
+type FileDepositStore struct {
+	path   string
+	lock   sync.Mutex
+	ids    map[string]struct{}
+	loaded bool
+}
+
+func NewFileDepositStore(path string) *FileDepositStore {
+	return &FileDepositStore{
+		path: path,
+		ids:  make(map[string]struct{}),
+	}
+}
+
+func (f *FileDepositStore) Has(_ context.Context, id string) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.load(); err != nil {
+		return false, err
+	}
+	_, ok := f.ids[id]
+	return ok, nil
+}
+
+func (f *FileDepositStore) Add(_ context.Context, id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.load(); err != nil {
+		return err
+	}
+	if _, ok := f.ids[id]; ok {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err = file.WriteString(id + "\n"); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	f.ids[id] = struct{}{}
+	return nil
+}
+
+func (f *FileDepositStore) load() error {
+	if f.loaded {
+		return nil
+	}
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		f.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			f.ids[line] = struct{}{}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	f.loaded = true
+	return nil
+}
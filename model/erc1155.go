@@ -0,0 +1,308 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/model/contract/erc1155"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"strings"
+)
+
+// Erc1155
+//
+//	@Description: an erc1155 multi token, the txs are sent like Token.TransferWithSigner
+type Erc1155 struct {
+	chain           *Chain
+	contractAddress string
+}
+
+func NewErc1155(chain *Chain, contractAddress string) *Erc1155 {
+	return &Erc1155{
+		chain:           chain,
+		contractAddress: contractAddress,
+	}
+}
+
+// BalanceOfCtx
+//
+//	@Description: the balance of the token id of the owner
+//	@receiver e
+//	@param ctx
+//	@param owner
+//	@param id
+//	@return string
+//	@return error
+func (e *Erc1155) BalanceOfCtx(ctx context.Context, owner, id string) (string, error) {
+	tokenId, err := parseUint(id)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(owner) {
+		return "", errors.New("address format is error")
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	balance, err := link.BalanceOf(opts, common.HexToAddress(owner), tokenId)
+	if err != nil {
+		return "", WrapRevertError(err, nil)
+	}
+	return balance.String(), nil
+}
+
+// BalanceOfBatchCtx
+//
+//	@Description: balanceOfBatch, the balance of owners[i] in ids[i]
+//	@receiver e
+//	@param ctx
+//	@param owners
+//	@param ids the same length as owners
+//	@return []string in the order of owners
+//	@return error
+func (e *Erc1155) BalanceOfBatchCtx(ctx context.Context, owners, ids []string) ([]string, error) {
+	if len(owners) != len(ids) {
+		return nil, errors.New("the length of owners and ids is different")
+	}
+	accounts := make([]common.Address, len(owners))
+	for i, owner := range owners {
+		if !util.IsValidAddress(owner) {
+			return nil, errors.New("address format is error")
+		}
+		accounts[i] = common.HexToAddress(owner)
+	}
+	tokenIds, err := parseUints(ids)
+	if err != nil {
+		return nil, err
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	balances, err := link.BalanceOfBatch(opts, accounts, tokenIds)
+	if err != nil {
+		return nil, WrapRevertError(err, nil)
+	}
+	if len(balances) != len(owners) {
+		return nil, errors.New("the result count of balanceOfBatch is wrong")
+	}
+	results := make([]string, len(balances))
+	for i, balance := range balances {
+		results[i] = balance.String()
+	}
+	return results, nil
+}
+
+// URICtx
+//
+//	@Description: the metadata uri of the token id, "{id}" in the uri is replaced like eip1155 describes
+//	@receiver e
+//	@param ctx
+//	@param id
+//	@return string
+//	@return error
+func (e *Erc1155) URICtx(ctx context.Context, id string) (string, error) {
+	tokenId, err := parseUint(id)
+	if err != nil {
+		return "", err
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	uri, err := link.Uri(opts, tokenId)
+	if err != nil {
+		return "", WrapRevertError(err, nil)
+	}
+	// lowercase hex, 0 padded to 64 chars, no 0x
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenId)), nil
+}
+
+// IsApprovedForAllCtx
+//
+//	@Description: the operator can transfer all tokens of the owner
+//	@receiver e
+//	@param ctx
+//	@param owner
+//	@param operator
+//	@return bool
+//	@return error
+func (e *Erc1155) IsApprovedForAllCtx(ctx context.Context, owner, operator string) (bool, error) {
+	if !util.IsValidAddress(owner) || !util.IsValidAddress(operator) {
+		return false, errors.New("address format is error")
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+	approved, err := link.IsApprovedForAll(opts, common.HexToAddress(owner), common.HexToAddress(operator))
+	if err != nil {
+		return false, WrapRevertError(err, nil)
+	}
+	return approved, nil
+}
+
+// SafeTransferFromCtx
+//
+//	@Description: safeTransferFrom, the receiver contract must implement onERC1155Received
+//	@receiver e
+//	@param ctx
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param id
+//	@param amount
+//	@param data hex, passed to onERC1155Received, can be empty
+//	@return hash
+//	@return err
+func (e *Erc1155) SafeTransferFromCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, id, amount, data string) (hash string, err error) {
+	tokenId, err := parseUint(id)
+	if err != nil {
+		return "", err
+	}
+	value, err := parseUint(amount)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(from) || !util.IsValidAddress(to) {
+		return "", errors.New("address format is error")
+	}
+	payload, err := decodeData(data)
+	if err != nil {
+		return "", err
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "safeTransferFrom", common.HexToAddress(from), common.HexToAddress(to), tokenId, value, payload)
+}
+
+// SafeBatchTransferFromCtx
+//
+//	@Description: safeBatchTransferFrom, many token ids in one tx
+//	@receiver e
+//	@param ctx
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param ids
+//	@param amounts the same length as ids
+//	@param data hex, passed to onERC1155BatchReceived, can be empty
+//	@return hash
+//	@return err
+func (e *Erc1155) SafeBatchTransferFromCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to string, ids, amounts []string, data string) (hash string, err error) {
+	if len(ids) == 0 || len(ids) != len(amounts) {
+		return "", errors.New("the length of ids and amounts is different")
+	}
+	tokenIds, err := parseUints(ids)
+	if err != nil {
+		return "", err
+	}
+	values, err := parseUints(amounts)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(from) || !util.IsValidAddress(to) {
+		return "", errors.New("address format is error")
+	}
+	payload, err := decodeData(data)
+	if err != nil {
+		return "", err
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "safeBatchTransferFrom", common.HexToAddress(from), common.HexToAddress(to), tokenIds, values, payload)
+}
+
+// SetApprovalForAllCtx
+//
+//	@Description: approve or revoke the operator for all tokens of the signer
+//	@receiver e
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@return hash
+//	@return err
+func (e *Erc1155) SetApprovalForAllCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool) (hash string, err error) {
+	if !util.IsValidAddress(operator) {
+		return "", errors.New("address format is error")
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "setApprovalForAll", common.HexToAddress(operator), approved)
+}
+
+func (e *Erc1155) caller(ctx context.Context) (*erc1155.ERC1155Caller, *bind.CallOpts, context.CancelFunc, error) {
+	if e.chain == nil {
+		return nil, nil, nil, errors.New("the chain node is empty")
+	}
+	if !util.IsValidAddress(e.contractAddress) {
+		return nil, nil, nil, errors.New("contract address format is error")
+	}
+	link, err := erc1155.NewERC1155Caller(common.HexToAddress(e.contractAddress), e.chain.Client())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	callCtx, cancel := e.chain.WithTimeout(ctx)
+	return link, &bind.CallOpts{Context: callCtx}, cancel, nil
+}
+
+func (e *Erc1155) send(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, method string, args ...interface{}) (string, error) {
+	if e.chain == nil {
+		return "", errors.New("the chain node is empty")
+	}
+	parsed, err := erc1155.ERC1155MetaData.GetAbi()
+	if err != nil {
+		return "", err
+	}
+	return NewToken(e.chain).sendContractCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "0", e.contractAddress, parsed, method, args...)
+}
+
+// parseUint
+//
+//	@Description: a uint256 in decimal, the abi packs the bigger ones modulo 2^256, so they are rejected
+//	@param value
+//	@return *big.Int
+//	@return error
+func parseUint(value string) (*big.Int, error) {
+	result, ok := new(big.Int).SetString(value, 10)
+	if !ok || result.Sign() < 0 || result.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid number: %s", value)
+	}
+	return result, nil
+}
+
+func parseUints(values []string) ([]*big.Int, error) {
+	results := make([]*big.Int, len(values))
+	for i, value := range values {
+		result, err := parseUint(value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func decodeData(data string) ([]byte, error) {
+	if data == "" {
+		return []byte{}, nil
+	}
+	return hexutil.Decode(data)
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc165"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+)
+
+// NftStandard the token standard detected by erc165
+type NftStandard string
+
+const (
+	NftStandardUnknown NftStandard = ""
+	NftStandardErc721  NftStandard = "erc721"
+	NftStandardErc1155 NftStandard = "erc1155"
+)
+
+// the interface ids of erc165
+var (
+	InterfaceIdErc165             = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	InterfaceIdErc721             = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	InterfaceIdErc721Metadata     = [4]byte{0x5b, 0x5e, 0x13, 0x9f}
+	InterfaceIdErc1155            = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+	InterfaceIdErc1155MetadataURI = [4]byte{0x0e, 0x89, 0x34, 0x1c}
+	interfaceIdInvalid            = [4]byte{0xff, 0xff, 0xff, 0xff}
+)
+
+// Erc165
+//
+//	@Description: the interface detection of a contract
+type Erc165 struct {
+	chain           *Chain
+	contractAddress string
+}
+
+func NewErc165(chain *Chain, contractAddress string) *Erc165 {
+	return &Erc165{
+		chain:           chain,
+		contractAddress: contractAddress,
+	}
+}
+
+func (e *Erc165) SupportsInterface(interfaceId [4]byte) (bool, error) {
+	return e.SupportsInterfaceCtx(context.Background(), interfaceId)
+}
+
+// SupportsInterfaceCtx
+//
+//	@Description: the contract implements erc165 and the interface, a contract without erc165 is not an error but false
+//	@receiver e
+//	@param ctx
+//	@param interfaceId
+//	@return bool
+//	@return error
+func (e *Erc165) SupportsInterfaceCtx(ctx context.Context, interfaceId [4]byte) (bool, error) {
+	if e.chain == nil {
+		return false, errors.New("the chain node is empty")
+	}
+	if !util.IsValidAddress(e.contractAddress) {
+		return false, errors.New("contract address format is error")
+	}
+	// erc165 is checked like the eip describes: supportsInterface(0x01ffc9a7) is true and supportsInterface(0xffffffff) is false
+	if ok, err := e.supportsInterface(ctx, InterfaceIdErc165); !ok || err != nil {
+		return false, err
+	}
+	if ok, err := e.supportsInterface(ctx, interfaceIdInvalid); ok || err != nil {
+		return false, err
+	}
+	if interfaceId == InterfaceIdErc165 {
+		return true, nil
+	}
+	return e.supportsInterface(ctx, interfaceId)
+}
+
+func (e *Erc165) Standard() (NftStandard, error) {
+	return e.StandardCtx(context.Background())
+}
+
+// StandardCtx
+//
+//	@Description: which nft standard the contract implements
+//	@receiver e
+//	@param ctx
+//	@return NftStandard NftStandardUnknown if it is neither erc721 nor erc1155
+//	@return error
+func (e *Erc165) StandardCtx(ctx context.Context) (NftStandard, error) {
+	ok, err := e.SupportsInterfaceCtx(ctx, InterfaceIdErc165)
+	if !ok || err != nil {
+		return NftStandardUnknown, err
+	}
+	for _, standard := range []struct {
+		interfaceId [4]byte
+		standard    NftStandard
+	}{
+		{InterfaceIdErc721, NftStandardErc721},
+		{InterfaceIdErc1155, NftStandardErc1155},
+	} {
+		ok, err = e.supportsInterface(ctx, standard.interfaceId)
+		if err != nil {
+			return NftStandardUnknown, err
+		}
+		if ok {
+			return standard.standard, nil
+		}
+	}
+	return NftStandardUnknown, nil
+}
+
+// supportsInterface
+//
+//	@Description: a revert or an empty result means false, only the node errors are returned
+//	@receiver e
+//	@param ctx
+//	@param interfaceId
+//	@return bool
+//	@return error
+func (e *Erc165) supportsInterface(ctx context.Context, interfaceId [4]byte) (bool, error) {
+	link, err := erc165.NewERC165Caller(common.HexToAddress(e.contractAddress), e.chain.Client())
+	if err != nil {
+		return false, err
+	}
+	callCtx, cancel := e.chain.WithTimeout(ctx)
+	defer cancel()
+	ok, err := link.SupportsInterface(&bind.CallOpts{Context: callCtx}, interfaceId)
+	if err == nil {
+		return ok, nil
+	}
+	// no contract, or the fallback function answers nothing
+	if errors.Is(err, bind.ErrNoCode) || strings.HasPrefix(err.Error(), "abi:") {
+		return false, nil
+	}
+	if _, isRevert := RevertData(err); isRevert || strings.Contains(err.Error(), "execution reverted") {
+		return false, nil
+	}
+	return false, err
+}
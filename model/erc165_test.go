@@ -0,0 +1,111 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc1155"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+const testNftAddress = "0xbd927011759b2c4f2602c3008f8ef3407db53473"
+
+// callStub
+//
+//	@Description: eth_call is answered by the handler with the call data
+type callStub struct {
+	handler func(data []byte) ([]byte, error)
+}
+
+func (c *callStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (c *callStub) Call(args map[string]interface{}, _ string) (hexutil.Bytes, error) {
+	input, _ := args["input"].(string)
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	return c.handler(data)
+}
+
+func supportsStub(interfaceIds ...[4]byte) *callStub {
+	return &callStub{handler: func(data []byte) ([]byte, error) {
+		result := make([]byte, 32)
+		for _, interfaceId := range interfaceIds {
+			if [4]byte(data[4:8]) == interfaceId {
+				result[31] = 1
+			}
+		}
+		return result, nil
+	}}
+}
+
+func TestErc165Standard(t *testing.T) {
+	standard, err := NewErc165(newStubChain(t, supportsStub(InterfaceIdErc165, InterfaceIdErc721)), testNftAddress).Standard()
+	require.Nil(t, err)
+	require.Equal(t, NftStandardErc721, standard)
+
+	standard, err = NewErc165(newStubChain(t, supportsStub(InterfaceIdErc165, InterfaceIdErc1155)), testNftAddress).Standard()
+	require.Nil(t, err)
+	require.Equal(t, NftStandardErc1155, standard)
+
+	// answers true for everything, it is not a valid erc165
+	standard, err = NewErc165(newStubChain(t, supportsStub(InterfaceIdErc165, InterfaceIdErc721, interfaceIdInvalid)), testNftAddress).Standard()
+	require.Nil(t, err)
+	require.Equal(t, NftStandardUnknown, standard)
+
+	// a contract without supportsInterface
+	reverted := &callStub{handler: func(data []byte) ([]byte, error) {
+		return nil, errors.New("execution reverted")
+	}}
+	ok, err := NewErc165(newStubChain(t, reverted), testNftAddress).SupportsInterface(InterfaceIdErc721)
+	require.Nil(t, err)
+	require.False(t, ok)
+}
+
+func TestErc1155URI(t *testing.T) {
+	parsed, err := erc1155.ERC1155MetaData.GetAbi()
+	require.Nil(t, err)
+	stub := &callStub{handler: func(data []byte) ([]byte, error) {
+		return parsed.Methods["uri"].Outputs.Pack("https://token-cdn-domain/{id}.json")
+	}}
+	uri, err := NewErc1155(newStubChain(t, stub), testNftAddress).URICtx(context.Background(), "314592")
+	require.Nil(t, err)
+	require.Equal(t, "https://token-cdn-domain/000000000000000000000000000000000000000000000000000000000004cce0.json", uri)
+
+	_, err = NewErc1155(newStubChain(t, stub), testNftAddress).BalanceOfBatchCtx(context.Background(), []string{testNftAddress}, nil)
+	require.NotNil(t, err)
+}
+
+func TestNftIsApprovedForAllReverted(t *testing.T) {
+	reverted := &callStub{handler: func(data []byte) ([]byte, error) {
+		return nil, errors.New("execution reverted")
+	}}
+	var revertErr *RevertError
+	_, err := NewErc721(newStubChain(t, reverted), testNftAddress).IsApprovedForAllCtx(context.Background(), testNonceAddress, testNftAddress)
+	require.True(t, errors.As(err, &revertErr))
+	_, err = NewErc1155(newStubChain(t, reverted), testNftAddress).IsApprovedForAllCtx(context.Background(), testNonceAddress, testNftAddress)
+	require.True(t, errors.As(err, &revertErr))
+}
+
+func TestParseUint(t *testing.T) {
+	_, err := parseUint("-1")
+	require.NotNil(t, err)
+	_, err = parseUint("1.5")
+	require.NotNil(t, err)
+	value, err := parseUint("0")
+	require.Nil(t, err)
+	require.Zero(t, value.Sign())
+
+	// 2^256 would be packed as 0
+	_, err = parseUint(new(big.Int).Lsh(common.Big1, 256).String())
+	require.NotNil(t, err)
+	value, err = parseUint(new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 256), common.Big1).String())
+	require.Nil(t, err)
+	require.Equal(t, 256, value.BitLen())
+}
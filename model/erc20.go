@@ -0,0 +1,511 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/model/contract/erc20permit"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// tokenMetadataCache the metadata never changes, keyed by chain id and token address
+var tokenMetadataCache = make(map[string]TokenMetadata)
+var tokenMetadataLock sync.RWMutex
+
+// Erc20
+//
+//	@Description: an erc20 token, the txs are sent like Token.TransferWithSigner, so they share the nonce manager and the fee oracle
+type Erc20 struct {
+	chain           *Chain
+	contractAddress string
+}
+
+func NewErc20(chain *Chain, contractAddress string) *Erc20 {
+	return &Erc20{
+		chain:           chain,
+		contractAddress: contractAddress,
+	}
+}
+
+// TokenMetadata
+//
+//	@Description: the metadata of an erc20 token
+type TokenMetadata struct {
+	Address  string
+	Name     string
+	Symbol   string
+	Decimals uint8
+}
+
+// PermitSignature
+//
+//	@Description: the eip2612 permit signed by the owner, anyone can submit it
+type PermitSignature struct {
+	Owner     string
+	Spender   string
+	Value     string
+	Nonce     string
+	Deadline  int64
+	V         uint8
+	R         [32]byte
+	S         [32]byte
+	Signature string // hex of r + s + v
+}
+
+// TransferCtx
+//
+//	@Description: transfer erc20 to the receiver, if gasLimit is empty it is estimated
+//	@receiver e
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param to
+//	@param amount the min unit of the token
+//	@return hash
+//	@return err
+func (e *Erc20) TransferCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, amount string) (hash string, err error) {
+	value, err := parseUint(amount)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(to) {
+		return "", errors.New("address format is error")
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "transfer", common.HexToAddress(to), value)
+}
+
+// ApproveCtx
+//
+//	@Description: approve the spender, amount 0 revokes the allowance
+//	@receiver e
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param spender
+//	@param amount
+//	@return hash
+//	@return err
+func (e *Erc20) ApproveCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, spender, amount string) (hash string, err error) {
+	value, err := parseUint(amount)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(spender) {
+		return "", errors.New("address format is error")
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "approve", common.HexToAddress(spender), value)
+}
+
+// TransferFromCtx
+//
+//	@Description: transfer the token of from by the allowance of the signer
+//	@receiver e
+//	@param ctx
+//	@param s the spender
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param amount
+//	@return hash
+//	@return err
+func (e *Erc20) TransferFromCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, amount string) (hash string, err error) {
+	value, err := parseUint(amount)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(from) || !util.IsValidAddress(to) {
+		return "", errors.New("address format is error")
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "transferFrom", common.HexToAddress(from), common.HexToAddress(to), value)
+}
+
+func (e *Erc20) Metadata() (TokenMetadata, error) {
+	return e.MetadataCtx(context.Background())
+}
+
+// MetadataCtx
+//
+//	@Description: name, symbol and decimals of the token, they are cached after the first query.
+//	some old tokens return bytes32 instead of string, eg: MKR
+//	@receiver e
+//	@param ctx
+//	@return TokenMetadata
+//	@return error
+func (e *Erc20) MetadataCtx(ctx context.Context) (TokenMetadata, error) {
+	if e.chain == nil {
+		return TokenMetadata{}, errors.New("the chain node is empty")
+	}
+	if !util.IsValidAddress(e.contractAddress) {
+		return TokenMetadata{}, errors.New("contract address format is error")
+	}
+	key := e.chain.ChainId.String() + ":" + strings.ToLower(common.HexToAddress(e.contractAddress).Hex())
+	tokenMetadataLock.RLock()
+	metadata, ok := tokenMetadataCache[key]
+	tokenMetadataLock.RUnlock()
+	if ok {
+		return metadata, nil
+	}
+
+	metadata = TokenMetadata{Address: common.HexToAddress(e.contractAddress).Hex()}
+	data, err := e.call(ctx, "decimals")
+	if err != nil {
+		return TokenMetadata{}, err
+	}
+	decimals := new(big.Int).SetBytes(data)
+	if len(data) != 32 || !decimals.IsUint64() || decimals.Uint64() > math.MaxUint8 {
+		return TokenMetadata{}, errors.New("invalid decimals of the token")
+	}
+	metadata.Decimals = uint8(decimals.Uint64())
+	if metadata.Name, err = e.callString(ctx, "name"); err != nil {
+		return TokenMetadata{}, err
+	}
+	if metadata.Symbol, err = e.callString(ctx, "symbol"); err != nil {
+		return TokenMetadata{}, err
+	}
+
+	tokenMetadataLock.Lock()
+	tokenMetadataCache[key] = metadata
+	tokenMetadataLock.Unlock()
+	return metadata, nil
+}
+
+// ParseAmountCtx
+//
+//	@Description: convert a human amount like "1.5" to the min unit by the decimals of the token
+//	@receiver e
+//	@param ctx
+//	@param amount
+//	@return string
+//	@return error
+func (e *Erc20) ParseAmountCtx(ctx context.Context, amount string) (string, error) {
+	metadata, err := e.MetadataCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, err := util.ParseUnits(amount, metadata.Decimals)
+	if err != nil {
+		return "", err
+	}
+	return value.String(), nil
+}
+
+// FormatAmountCtx
+//
+//	@Description: convert the min unit to a human amount by the decimals of the token
+//	@receiver e
+//	@param ctx
+//	@param amount
+//	@return decimal.Decimal
+//	@return error
+func (e *Erc20) FormatAmountCtx(ctx context.Context, amount string) (decimal.Decimal, error) {
+	value, err := parseUint(amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	metadata, err := e.MetadataCtx(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return util.FormatUnits(value, metadata.Decimals), nil
+}
+
+// callString
+//
+//	@Description: decode the string result, or the bytes32 result of the non-standard tokens
+//	@receiver e
+//	@param ctx
+//	@param method
+//	@return string
+//	@return error
+func (e *Erc20) callString(ctx context.Context, method string) (string, error) {
+	data, err := e.call(ctx, method)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 32 {
+		value := string(bytes.TrimRight(data, "\x00"))
+		if !utf8.ValidString(value) {
+			return "", fmt.Errorf("invalid %s of the token", method)
+		}
+		return value, nil
+	}
+	values, err := abi.Arguments{{Type: stringType}}.Unpack(data)
+	if err != nil {
+		return "", err
+	}
+	return values[0].(string), nil
+}
+
+var stringType, _ = abi.NewType("string", "", nil)
+
+func (e *Erc20) call(ctx context.Context, method string) ([]byte, error) {
+	parsed, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := e.chain.WithTimeout(ctx)
+	defer cancel()
+	to := common.HexToAddress(e.contractAddress)
+	result, err := e.chain.Client().CallContract(callCtx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, WrapRevertError(err, parsed)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("the token has no %s", method)
+	}
+	return result, nil
+}
+
+// SignPermitCtx
+//
+//	@Description: sign the eip2612 permit, the eip712 domain is read from the token and checked against DOMAIN_SEPARATOR
+//	@receiver e
+//	@param ctx
+//	@param s the owner
+//	@param spender
+//	@param amount
+//	@param deadline unix second
+//	@return *PermitSignature
+//	@return error
+func (e *Erc20) SignPermitCtx(ctx context.Context, s signer.Signer, spender, amount string, deadline int64) (*PermitSignature, error) {
+	if s == nil {
+		return nil, errors.New("param is error")
+	}
+	value, err := parseUint(amount)
+	if err != nil {
+		return nil, err
+	}
+	if !util.IsValidAddress(spender) {
+		return nil, errors.New("address format is error")
+	}
+	link, err := erc20permit.NewERC20PermitCaller(common.HexToAddress(e.contractAddress), e.chain.Client())
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := e.chain.WithTimeout(ctx)
+	defer cancel()
+	opts := &bind.CallOpts{Context: callCtx}
+
+	owner := s.Address()
+	nonce, err := link.Nonces(opts, owner)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := e.permitDomain(opts, link)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData := &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domain,
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  common.HexToAddress(spender).Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": big.NewInt(deadline).String(),
+		},
+	}
+	signature, err := s.SignTypedData(ctx, typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	permit := &PermitSignature{
+		Owner:     owner.Hex(),
+		Spender:   common.HexToAddress(spender).Hex(),
+		Value:     value.String(),
+		Nonce:     nonce.String(),
+		Deadline:  deadline,
+		V:         signature[64],
+		Signature: hexutil.Encode(signature),
+	}
+	copy(permit.R[:], signature[:32])
+	copy(permit.S[:], signature[32:64])
+	return permit, nil
+}
+
+// permitDomain
+//
+//	@Description: eip5267 eip712Domain() first, then name() and version(), the version is "1" if the token has no version()
+//	@receiver e
+//	@param opts
+//	@param link
+//	@return apitypes.TypedDataDomain
+//	@return error the domain doesn't match DOMAIN_SEPARATOR
+func (e *Erc20) permitDomain(opts *bind.CallOpts, link *erc20permit.ERC20PermitCaller) (apitypes.TypedDataDomain, error) {
+	domain := apitypes.TypedDataDomain{
+		ChainId:           (*math.HexOrDecimal256)(e.chain.ChainId),
+		VerifyingContract: common.HexToAddress(e.contractAddress).Hex(),
+	}
+	if eip712, err := link.Eip712Domain(opts); err == nil {
+		domain.Name, domain.Version = eip712.Name, eip712.Version
+	} else {
+		if domain.Name, err = link.Name(opts); err != nil {
+			return domain, err
+		}
+		if domain.Version, err = link.Version(opts); err != nil {
+			domain.Version = "1"
+		}
+	}
+
+	expect, err := link.DOMAINSEPARATOR(opts)
+	if err != nil {
+		return domain, err
+	}
+	separator, err := (&apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+		},
+		Domain: domain,
+	}).HashStruct("EIP712Domain", domain.Map())
+	if err != nil {
+		return domain, err
+	}
+	if common.BytesToHash(separator) != common.Hash(expect) {
+		return domain, errors.New("the eip712 domain of the token is not supported")
+	}
+	return domain, nil
+}
+
+// PermitCtx
+//
+//	@Description: submit the permit signed by the owner, the sender can be anyone
+//	@receiver e
+//	@param ctx
+//	@param s the sender of the tx
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param permit
+//	@return hash
+//	@return err
+func (e *Erc20) PermitCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas string, permit *PermitSignature) (hash string, err error) {
+	if permit == nil {
+		return "", errors.New("permit is empty")
+	}
+	value, err := parseUint(permit.Value)
+	if err != nil {
+		return "", err
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "permit",
+		common.HexToAddress(permit.Owner), common.HexToAddress(permit.Spender), value, big.NewInt(permit.Deadline), permit.V, permit.R, permit.S)
+}
+
+// send
+//
+//	@Description: pack the call of the method, estimate the gas limit if it is empty, then send it like a transfer
+//	@receiver e
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param method
+//	@param args
+//	@return string
+//	@return error
+func (e *Erc20) send(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, method string, args ...interface{}) (string, error) {
+	if e.chain == nil {
+		return "", errors.New("the chain node is empty")
+	}
+	if s == nil {
+		return "", errors.New("param is error")
+	}
+	if !util.IsValidAddress(e.contractAddress) {
+		return "", errors.New("contract address format is error")
+	}
+
+	contractAbi := erc20.ERC20MetaData
+	if method == "permit" {
+		contractAbi = erc20permit.ERC20PermitMetaData
+	}
+	parsed, err := contractAbi.GetAbi()
+	if err != nil {
+		return "", err
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return "", err
+	}
+
+	token := NewToken(e.chain)
+	if gasLimit == "" {
+		gasLimit, err = token.EstimateGasLimitCtx(ctx, s.Address().Hex(), e.contractAddress, gasPrice, "0", data)
+		if err != nil {
+			// the chain has wrapped it without abi, decode the custom errors again
+			var revertErr *RevertError
+			if errors.As(err, &revertErr) && len(revertErr.Data) > 0 {
+				decoded := DecodeRevert(revertErr.Data, &erc20ErrorsAbi)
+				decoded.Err = revertErr.Err
+				return "", decoded
+			}
+			return "", err
+		}
+	}
+	return token.TransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "0", e.contractAddress, hexutil.Encode(data))
+}
+
+// erc20ErrorsAbi the custom errors of erc6093 and erc2612, they are not in the abi of the bindings,
+// eg: the tokens of openzeppelin 5
+var erc20ErrorsAbi, _ = abi.JSON(strings.NewReader(`[
+	{"type":"error","name":"ERC20InsufficientBalance","inputs":[{"name":"sender","type":"address"},{"name":"balance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+	{"type":"error","name":"ERC20InvalidSender","inputs":[{"name":"sender","type":"address"}]},
+	{"type":"error","name":"ERC20InvalidReceiver","inputs":[{"name":"receiver","type":"address"}]},
+	{"type":"error","name":"ERC20InsufficientAllowance","inputs":[{"name":"spender","type":"address"},{"name":"allowance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+	{"type":"error","name":"ERC20InvalidApprover","inputs":[{"name":"approver","type":"address"}]},
+	{"type":"error","name":"ERC20InvalidSpender","inputs":[{"name":"spender","type":"address"}]},
+	{"type":"error","name":"ERC2612ExpiredSignature","inputs":[{"name":"deadline","type":"uint256"}]},
+	{"type":"error","name":"ERC2612InvalidSigner","inputs":[{"name":"signer","type":"address"},{"name":"owner","type":"address"}]}
+]`))
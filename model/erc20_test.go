@@ -0,0 +1,246 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc20"
+	"github.com/bitxx/evm-utils/model/contract/erc20permit"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+const testPermitToken = "0x3E4511645086a6fabECbAf1c3eE152C067f0AedA"
+
+// permitStub
+//
+//	@Description: a token without eip712Domain() and version(), like the old permit tokens
+type permitStub struct {
+	separator common.Hash
+}
+
+func (p *permitStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(17000)), nil
+}
+
+func (p *permitStub) Call(args map[string]interface{}, _ string) (hexutil.Bytes, error) {
+	input, _ := args["input"].(string)
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := erc20permit.ERC20PermitMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "nonces":
+		return method.Outputs.Pack(big.NewInt(3))
+	case "name":
+		return method.Outputs.Pack("Test")
+	case "DOMAIN_SEPARATOR":
+		return method.Outputs.Pack(p.separator)
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func testPermitDomain(version string) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              "Test",
+		Version:           version,
+		ChainId:           (*math.HexOrDecimal256)(big.NewInt(17000)),
+		VerifyingContract: common.HexToAddress(testPermitToken).Hex(),
+	}
+}
+
+func testDomainSeparator(t *testing.T, domain apitypes.TypedDataDomain) common.Hash {
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "address"},
+		}},
+		Domain: domain,
+	}
+	separator, err := typedData.HashStruct("EIP712Domain", domain.Map())
+	require.Nil(t, err)
+	return common.BytesToHash(separator)
+}
+
+func TestSignPermit(t *testing.T) {
+	stub := &permitStub{separator: testDomainSeparator(t, testPermitDomain("1"))}
+	chain := newStubChain(t, stub)
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+
+	spender := "0x8B63293748e058F47a31c0D2Af0B1b3FeDdc4D4C"
+	permit, err := NewErc20(chain, testPermitToken).SignPermitCtx(context.Background(), s, spender, "1000", 1700000000)
+	require.Nil(t, err)
+	require.Equal(t, "3", permit.Nonce)
+	require.Equal(t, s.Address().Hex(), permit.Owner)
+	require.Contains(t, []uint8{27, 28}, permit.V)
+
+	// the signer of the permit is recovered by the contract from the same typed data
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      testPermitDomain("1"),
+		Message: apitypes.TypedDataMessage{
+			"owner":    permit.Owner,
+			"spender":  spender,
+			"value":    "1000",
+			"nonce":    "3",
+			"deadline": "1700000000",
+		},
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.Nil(t, err)
+	signature := append(append(permit.R[:], permit.S[:]...), permit.V-27)
+	pub, err := crypto.SigToPub(hash, signature)
+	require.Nil(t, err)
+	require.Equal(t, s.Address(), crypto.PubkeyToAddress(*pub))
+	require.True(t, bytes.Equal(hexutil.MustDecode(permit.Signature)[:64], signature[:64]))
+}
+
+func TestSignPermitDomainMismatch(t *testing.T) {
+	stub := &permitStub{separator: testDomainSeparator(t, testPermitDomain("2"))}
+	chain := newStubChain(t, stub)
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+
+	_, err = NewErc20(chain, testPermitToken).SignPermitCtx(context.Background(), s, "0x8B63293748e058F47a31c0D2Af0B1b3FeDdc4D4C", "1000", 1700000000)
+	require.NotNil(t, err)
+}
+
+// metadataStub
+//
+//	@Description: a token like MKR, name() returns bytes32
+type metadataStub struct {
+	lock  sync.Mutex
+	calls int
+}
+
+func (m *metadataStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (m *metadataStub) Call(args map[string]interface{}, _ string) (hexutil.Bytes, error) {
+	m.lock.Lock()
+	m.calls++
+	m.lock.Unlock()
+
+	input, _ := args["input"].(string)
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "decimals":
+		return common.LeftPadBytes(big.NewInt(18).Bytes(), 32), nil
+	case "name":
+		return common.RightPadBytes([]byte("Maker"), 32), nil
+	case "symbol":
+		return method.Outputs.Pack("MKR")
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestErc20Metadata(t *testing.T) {
+	stub := &metadataStub{}
+	token := NewErc20(newStubChain(t, stub), "0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+
+	metadata, err := token.Metadata()
+	require.Nil(t, err)
+	require.Equal(t, "Maker", metadata.Name)
+	require.Equal(t, "MKR", metadata.Symbol)
+	require.Equal(t, uint8(18), metadata.Decimals)
+
+	// cached
+	calls := stub.calls
+	_, err = token.Metadata()
+	require.Nil(t, err)
+	require.Equal(t, calls, stub.calls)
+
+	value, err := token.ParseAmountCtx(context.Background(), "1.5")
+	require.Nil(t, err)
+	require.Equal(t, "1500000000000000000", value)
+	amount, err := token.FormatAmountCtx(context.Background(), value)
+	require.Nil(t, err)
+	require.Equal(t, "1.5", amount.String())
+}
+
+// estimateRevertStub
+//
+//	@Description: eth_estimateGas reverts with the revert data
+type estimateRevertStub struct {
+	ethStub
+	data string
+}
+
+func (r *estimateRevertStub) EstimateGas(_ map[string]interface{}) (hexutil.Uint64, error) {
+	return 0, &rpcDataError{data: r.data}
+}
+
+func TestErc20CustomError(t *testing.T) {
+	// ERC20InsufficientBalance of openzeppelin 5
+	abiErr := erc20ErrorsAbi.Errors["ERC20InsufficientBalance"]
+	args, err := abiErr.Inputs.Pack(common.HexToAddress(testNonceAddress), big.NewInt(1), big.NewInt(2))
+	require.Nil(t, err)
+	stub := &estimateRevertStub{data: hexutil.Encode(append(common.CopyBytes(abiErr.ID[:4]), args...))}
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+
+	_, err = NewErc20(newStubChain(t, stub), testPermitToken).TransferCtx(context.Background(), s, "", "1000000000", "", "", testNonceAddress, "2")
+	var revertErr *RevertError
+	require.True(t, errors.As(err, &revertErr))
+	require.Equal(t, "ERC20InsufficientBalance", revertErr.ErrorName)
+	require.Equal(t, []interface{}{common.HexToAddress(testNonceAddress), big.NewInt(1), big.NewInt(2)}, revertErr.Args)
+}
+
+func TestErc20AmountOverflow(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	erc := NewErc20(newStubChain(t, &ethStub{}), testPermitToken)
+
+	// 2^256+1 would be sent as 1
+	amount := new(big.Int).Add(math.BigPow(2, 256), common.Big1).String()
+	_, err = erc.TransferCtx(context.Background(), s, "", "1000000000", "", "", testNonceAddress, amount)
+	require.NotNil(t, err)
+	_, err = erc.ApproveCtx(context.Background(), s, "", "1000000000", "", "", testNonceAddress, amount)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/contract/erc721"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Erc721
+//
+//	@Description: an erc721 nft, the txs are sent like Token.TransferWithSigner
+type Erc721 struct {
+	chain           *Chain
+	contractAddress string
+}
+
+func NewErc721(chain *Chain, contractAddress string) *Erc721 {
+	return &Erc721{
+		chain:           chain,
+		contractAddress: contractAddress,
+	}
+}
+
+// OwnerOfCtx
+//
+//	@Description: the owner of the nft
+//	@receiver e
+//	@param ctx
+//	@param tokenId
+//	@return string
+//	@return error
+func (e *Erc721) OwnerOfCtx(ctx context.Context, tokenId string) (string, error) {
+	id, err := parseUint(tokenId)
+	if err != nil {
+		return "", err
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	owner, err := link.OwnerOf(opts, id)
+	if err != nil {
+		return "", WrapRevertError(err, nil)
+	}
+	return owner.Hex(), nil
+}
+
+// BalanceOfCtx
+//
+//	@Description: the count of the nfts of the owner
+//	@receiver e
+//	@param ctx
+//	@param owner
+//	@return string
+//	@return error
+func (e *Erc721) BalanceOfCtx(ctx context.Context, owner string) (string, error) {
+	if !util.IsValidAddress(owner) {
+		return "", errors.New("address format is error")
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	balance, err := link.BalanceOf(opts, common.HexToAddress(owner))
+	if err != nil {
+		return "", WrapRevertError(err, nil)
+	}
+	return balance.String(), nil
+}
+
+// TokenURICtx
+//
+//	@Description: the metadata uri of the nft
+//	@receiver e
+//	@param ctx
+//	@param tokenId
+//	@return string
+//	@return error
+func (e *Erc721) TokenURICtx(ctx context.Context, tokenId string) (string, error) {
+	id, err := parseUint(tokenId)
+	if err != nil {
+		return "", err
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	uri, err := link.TokenURI(opts, id)
+	if err != nil {
+		return "", WrapRevertError(err, nil)
+	}
+	return uri, nil
+}
+
+// IsApprovedForAllCtx
+//
+//	@Description: the operator can transfer all nfts of the owner
+//	@receiver e
+//	@param ctx
+//	@param owner
+//	@param operator
+//	@return bool
+//	@return error
+func (e *Erc721) IsApprovedForAllCtx(ctx context.Context, owner, operator string) (bool, error) {
+	if !util.IsValidAddress(owner) || !util.IsValidAddress(operator) {
+		return false, errors.New("address format is error")
+	}
+	link, opts, cancel, err := e.caller(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+	approved, err := link.IsApprovedForAll(opts, common.HexToAddress(owner), common.HexToAddress(operator))
+	if err != nil {
+		return false, WrapRevertError(err, nil)
+	}
+	return approved, nil
+}
+
+// SafeTransferFromCtx
+//
+//	@Description: safeTransferFrom, the receiver contract must implement onERC721Received
+//	@receiver e
+//	@param ctx
+//	@param s the owner or an approved operator
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param from
+//	@param to
+//	@param tokenId
+//	@param data hex, passed to onERC721Received, can be empty
+//	@return hash
+//	@return err
+func (e *Erc721) SafeTransferFromCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, from, to, tokenId, data string) (hash string, err error) {
+	id, err := parseUint(tokenId)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsValidAddress(from) || !util.IsValidAddress(to) {
+		return "", errors.New("address format is error")
+	}
+	if data == "" {
+		return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "safeTransferFrom", common.HexToAddress(from), common.HexToAddress(to), id)
+	}
+	payload, err := hexutil.Decode(data)
+	if err != nil {
+		return "", err
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "safeTransferFrom0", common.HexToAddress(from), common.HexToAddress(to), id, payload)
+}
+
+// SetApprovalForAllCtx
+//
+//	@Description: approve or revoke the operator for all nfts of the signer
+//	@receiver e
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param operator
+//	@param approved
+//	@return hash
+//	@return err
+func (e *Erc721) SetApprovalForAllCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, operator string, approved bool) (hash string, err error) {
+	if !util.IsValidAddress(operator) {
+		return "", errors.New("address format is error")
+	}
+	return e.send(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "setApprovalForAll", common.HexToAddress(operator), approved)
+}
+
+func (e *Erc721) caller(ctx context.Context) (*erc721.ERC721Caller, *bind.CallOpts, context.CancelFunc, error) {
+	if e.chain == nil {
+		return nil, nil, nil, errors.New("the chain node is empty")
+	}
+	if !util.IsValidAddress(e.contractAddress) {
+		return nil, nil, nil, errors.New("contract address format is error")
+	}
+	link, err := erc721.NewERC721Caller(common.HexToAddress(e.contractAddress), e.chain.Client())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	callCtx, cancel := e.chain.WithTimeout(ctx)
+	return link, &bind.CallOpts{Context: callCtx}, cancel, nil
+}
+
+func (e *Erc721) send(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, method string, args ...interface{}) (string, error) {
+	if e.chain == nil {
+		return "", errors.New("the chain node is empty")
+	}
+	parsed, err := erc721.ERC721MetaData.GetAbi()
+	if err != nil {
+		return "", err
+	}
+	return NewToken(e.chain).sendContractCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, "0", e.contractAddress, parsed, method, args...)
+}
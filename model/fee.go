@@ -0,0 +1,135 @@
+package model
+
+import (
+	"context"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/model/types"
+	"github.com/ethereum/go-ethereum"
+	"math/big"
+	"sort"
+)
+
+func (c *Chain) EstimateFees() (*types.FeeSuggestion, error) {
+	return c.EstimateFeesCtx(context.Background())
+}
+
+// EstimateFeesCtx
+//
+//	@Description: fee oracle, compute slow/standard/fast fees from the base fee trend and the reward percentiles of recent blocks
+//	@receiver c
+//	@param ctx
+//	@return *types.FeeSuggestion the legacy gas price only if the node doesn't support eth_feeHistory or there is no base fee
+//	@return error the other errors of eth_feeHistory, eg: timeouts, they never fall back to the legacy gas price
+func (c *Chain) EstimateFeesCtx(ctx context.Context) (*types.FeeSuggestion, error) {
+	ctx, cancel := c.WithTimeout(ctx)
+	defer cancel()
+
+	percentiles := []float64{config.FeeRewardPercentileSlow, config.FeeRewardPercentileStandard, config.FeeRewardPercentileFast}
+	history, err := c.Client().FeeHistory(ctx, config.FeeHistoryBlocks, nil, percentiles)
+	if err != nil && !isMethodNotFound(err) {
+		return nil, err
+	}
+	if err != nil || len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil || history.BaseFee[len(history.BaseFee)-1].Sign() <= 0 {
+		// the chain doesn't support eip1559
+		return c.estimateLegacyFees(ctx)
+	}
+
+	// the last one is the base fee of the next block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	avgBaseFee := new(big.Int)
+	for _, fee := range history.BaseFee[:len(history.BaseFee)-1] {
+		avgBaseFee.Add(avgBaseFee, fee)
+	}
+	extraBlocks := 0
+	if len(history.BaseFee) > 1 {
+		avgBaseFee.Div(avgBaseFee, big.NewInt(int64(len(history.BaseFee)-1)))
+		if baseFee.Cmp(avgBaseFee) > 0 {
+			// base fee is rising, leave more room
+			extraBlocks = 1
+		}
+	}
+
+	tips := make([]*big.Int, len(percentiles))
+	for i := range percentiles {
+		tips[i] = medianReward(history, i)
+	}
+	// eth_maxPriorityFeePerGas is the floor of standard and fast
+	suggestTip, err := c.Client().SuggestGasTipCap(ctx)
+	if err == nil && suggestTip != nil {
+		for i := 1; i < len(tips); i++ {
+			if tips[i].Cmp(suggestTip) < 0 {
+				tips[i] = new(big.Int).Set(suggestTip)
+			}
+		}
+		if tips[0].Sign() == 0 {
+			tips[0] = new(big.Int).Set(suggestTip)
+		}
+	}
+
+	return &types.FeeSuggestion{
+		BaseFee:  baseFee.String(),
+		Slow:     dynamicFee(baseFee, tips[0], config.FeeBaseFeeBlocksSlow+extraBlocks),
+		Standard: dynamicFee(baseFee, tips[1], config.FeeBaseFeeBlocksStandard+extraBlocks),
+		Fast:     dynamicFee(baseFee, tips[2], config.FeeBaseFeeBlocksFast+extraBlocks),
+	}, nil
+}
+
+func (c *Chain) estimateLegacyFees(ctx context.Context) (*types.FeeSuggestion, error) {
+	gasPrice, err := c.Client().SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fast := new(big.Int).Mul(gasPrice, big.NewInt(config.FeeLegacyFastPercent))
+	fast.Div(fast, big.NewInt(100))
+	return &types.FeeSuggestion{
+		IsLegacy: true,
+		Slow:     types.FeeEstimate{MaxFeePerGas: gasPrice.String()},
+		Standard: types.FeeEstimate{MaxFeePerGas: gasPrice.String()},
+		Fast:     types.FeeEstimate{MaxFeePerGas: fast.String()},
+	}, nil
+}
+
+// medianReward
+//
+//	@Description: the median of the rewards at one percentile, empty blocks are ignored
+//	@param history
+//	@param index index of the percentile
+//	@return *big.Int
+func medianReward(history *ethereum.FeeHistory, index int) *big.Int {
+	var rewards []*big.Int
+	for i, blockRewards := range history.Reward {
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if index < len(blockRewards) && blockRewards[index] != nil {
+			rewards = append(rewards, blockRewards[index])
+		}
+	}
+	if len(rewards) == 0 {
+		return new(big.Int)
+	}
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+	return new(big.Int).Set(rewards[len(rewards)/2])
+}
+
+// dynamicFee
+//
+//	@Description: maxFeePerGas = baseFee * 1.125^blocks + tip, the tx is still valid after base fee rising for blocks
+//	@param baseFee
+//	@param tip
+//	@param blocks
+//	@return types.FeeEstimate
+func dynamicFee(baseFee, tip *big.Int, blocks int) types.FeeEstimate {
+	maxFee := new(big.Int).Set(baseFee)
+	for i := 0; i < blocks; i++ {
+		maxFee.Mul(maxFee, big.NewInt(9))
+		maxFee.Div(maxFee, big.NewInt(8))
+	}
+	maxFee.Add(maxFee, tip)
+	return types.FeeEstimate{
+		MaxFeePerGas:         maxFee.String(),
+		MaxPriorityFeePerGas: tip.String(),
+	}
+}
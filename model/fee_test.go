@@ -0,0 +1,47 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// legacyFeeStub
+//
+//	@Description: a node without eth_feeHistory
+type legacyFeeStub struct {
+	ethStub
+}
+
+func (l *legacyFeeStub) GasPrice() (*hexutil.Big, error) {
+	return (*hexutil.Big)(big.NewInt(1000)), nil
+}
+
+// feeHistoryErrorStub
+//
+//	@Description: eth_feeHistory fails like a rate limit of the node
+type feeHistoryErrorStub struct {
+	legacyFeeStub
+}
+
+func (f *feeHistoryErrorStub) FeeHistory(_ hexutil.Uint64, _ string, _ []float64) (map[string]interface{}, error) {
+	return nil, errors.New("429 too many requests")
+}
+
+func TestEstimateFeesLegacy(t *testing.T) {
+	fees, err := newStubChain(t, &legacyFeeStub{}).EstimateFeesCtx(context.Background())
+	require.Nil(t, err)
+	require.True(t, fees.IsLegacy)
+	require.Equal(t, "1000", fees.Standard.MaxFeePerGas)
+	require.Equal(t, "1200", fees.Fast.MaxFeePerGas)
+}
+
+func TestEstimateFeesError(t *testing.T) {
+	// the eip1559 chain is not taken as legacy
+	_, err := newStubChain(t, &feeHistoryErrorStub{}).EstimateFeesCtx(context.Background())
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "429")
+}
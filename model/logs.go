@@ -0,0 +1,239 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/bitxx/evm-utils/util/abiutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// LogQuery
+//
+//	@Description: the filter of eth_getLogs
+type LogQuery struct {
+	Addresses  []string   // the contracts, empty is any
+	Topics     [][]string // topics[i] matches any of them, empty is any, topics[0] is the event id
+	FromBlock  uint64
+	ToBlock    uint64 // 0 is the latest block
+	BlockRange uint64 // the max blocks of one eth_getLogs, 0 is config.LogsBlockRange
+}
+
+// EventLog
+//
+//	@Description: a log and its decoded event
+type EventLog struct {
+	Address     string
+	Topics      []string
+	Data        []byte
+	BlockNumber uint64
+	BlockHash   string
+	TxHash      string
+	TxIndex     uint
+	LogIndex    uint
+	Removed     bool // the log is reverted by a reorg
+
+	Event     string                 // the event name, empty if no abi decodes the log
+	Signature string                 // eg: Transfer(address,address,uint256)
+	Args      []abiutil.Arg          // in the order of the abi
+	Fields    map[string]interface{} // the args by name, the unnamed args are keyed by their index
+}
+
+func (c *Chain) FilterLogs(query LogQuery, abis ...*abi.ABI) ([]EventLog, error) {
+	return c.FilterLogsCtx(context.Background(), query, abis...)
+}
+
+// FilterLogsCtx
+//
+//	@Description: the logs in the block range, the range is queried by parts of BlockRange blocks,
+//	a part is split in half again if the node says the results or the range are too large
+//	@receiver c
+//	@param ctx
+//	@param query
+//	@param abis decode the logs by the first abi which has the event, the logs are kept undecoded if none has it
+//	@return []EventLog in the order of the chain
+//	@return error
+func (c *Chain) FilterLogsCtx(ctx context.Context, query LogQuery, abis ...*abi.ABI) ([]EventLog, error) {
+	filter, err := query.filterQuery()
+	if err != nil {
+		return nil, err
+	}
+	to := query.ToBlock
+	if to == 0 {
+		callCtx, cancel := c.WithTimeout(ctx)
+		to, err = c.Client().BlockNumber(callCtx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if query.FromBlock > to {
+		return nil, fmt.Errorf("from block %d is greater than to block %d", query.FromBlock, to)
+	}
+	blockRange := query.BlockRange
+	if blockRange == 0 {
+		blockRange = config.LogsBlockRange
+	}
+
+	results := make([]EventLog, 0)
+	for from := query.FromBlock; from <= to; from += blockRange {
+		end := from + blockRange - 1
+		if end > to || end < from {
+			end = to
+		}
+		logs, err := c.filterLogs(ctx, filter, from, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			results = append(results, decodeEventLog(log, abis))
+		}
+		if end == to {
+			break
+		}
+	}
+	return results, nil
+}
+
+// filterLogs
+//
+//	@Description: eth_getLogs from the block to the block, split in half if the node limits the results or the range
+//	@receiver c
+//	@param ctx
+//	@param filter
+//	@param from
+//	@param to
+//	@return []types.Log
+//	@return error
+func (c *Chain) filterLogs(ctx context.Context, filter ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	filter.FromBlock = new(big.Int).SetUint64(from)
+	filter.ToBlock = new(big.Int).SetUint64(to)
+	callCtx, cancel := c.WithTimeout(ctx)
+	logs, err := c.Client().FilterLogs(callCtx, filter)
+	cancel()
+	if err == nil {
+		return logs, nil
+	}
+	if from >= to || !IsLogsLimitError(err) {
+		return nil, err
+	}
+
+	middle := from + (to-from)/2
+	left, err := c.filterLogs(ctx, filter, from, middle)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.filterLogs(ctx, filter, middle+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// IsLogsLimitError
+//
+//	@Description: eth_getLogs failed because the results or the block range are too large for the node,
+//	the rate limit is not, splitting the range only sends more requests
+//	@param err
+//	@return bool
+func IsLogsLimitError(err error) bool {
+	if err == nil || isRateLimitError(err) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, limit := range []string{
+		"query returned more than", // geth, infura
+		"block range",              // eg: exceed maximum block range
+		"range is too large",
+		"range too large",
+		"is limited to", // quicknode
+		"response size exceeded",
+	} {
+		if strings.Contains(msg, limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRateLimitError
+//
+//	@Description: http 429, or the json rpc error of the rate limit, eg: -32005 rate limit exceeded
+//	@param err
+//	@return bool
+func isRateLimitError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests")
+}
+
+func (q LogQuery) filterQuery() (ethereum.FilterQuery, error) {
+	filter := ethereum.FilterQuery{}
+	for _, address := range q.Addresses {
+		if !util.IsValidAddress(address) {
+			return filter, errors.New("address format is error")
+		}
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(address))
+	}
+	for _, topics := range q.Topics {
+		hashes := make([]common.Hash, 0, len(topics))
+		for _, topic := range topics {
+			bytes, err := util.HexDecodeString(topic)
+			if err != nil || len(bytes) != util.HashLength {
+				return filter, fmt.Errorf("invalid topic: %s", topic)
+			}
+			hashes = append(hashes, common.BytesToHash(bytes))
+		}
+		filter.Topics = append(filter.Topics, hashes)
+	}
+	return filter, nil
+}
+
+func decodeEventLog(log types.Log, abis []*abi.ABI) EventLog {
+	result := EventLog{
+		Address:     log.Address.Hex(),
+		Topics:      make([]string, len(log.Topics)),
+		Data:        log.Data,
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     log.TxIndex,
+		LogIndex:    log.Index,
+		Removed:     log.Removed,
+	}
+	for i, topic := range log.Topics {
+		result.Topics[i] = topic.Hex()
+	}
+	for _, contractAbi := range abis {
+		event, err := abiutil.DecodeLog(contractAbi, log.Topics, log.Data)
+		if err != nil {
+			// not the event of this abi, or the same id with other indexed args
+			continue
+		}
+		result.Event = event.Name
+		result.Signature = event.Signature
+		result.Args = event.Args
+		result.Fields = make(map[string]interface{}, len(event.Args))
+		for i, arg := range event.Args {
+			name := arg.Name
+			if name == "" {
+				name = fmt.Sprint(i)
+			}
+			result.Fields[name] = arg.Value
+		}
+		break
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/util/abiutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// logsStub
+//
+//	@Description: one transfer log in every block, the node refuses more than maxRange blocks
+type logsStub struct {
+	lock     sync.Mutex
+	maxRange uint64
+	latest   uint64
+	ranges   [][2]uint64
+	limited  bool // rate limited
+}
+
+func (l *logsStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (l *logsStub) BlockNumber() (hexutil.Uint64, error) {
+	return hexutil.Uint64(l.latest), nil
+}
+
+func (l *logsStub) GetLogs(args map[string]interface{}) ([]types.Log, error) {
+	from, err := hexutil.DecodeUint64(args["fromBlock"].(string))
+	if err != nil {
+		return nil, err
+	}
+	to, err := hexutil.DecodeUint64(args["toBlock"].(string))
+	if err != nil {
+		return nil, err
+	}
+	l.lock.Lock()
+	l.ranges = append(l.ranges, [2]uint64{from, to})
+	limited := l.limited
+	l.lock.Unlock()
+	if limited {
+		return nil, errors.New("rate limit exceeded")
+	}
+	if to-from+1 > l.maxRange {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	transfer := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	from32 := common.BytesToHash(common.HexToAddress(testNonceAddress).Bytes())
+	to32 := common.BytesToHash(common.HexToAddress(testNftAddress).Bytes())
+	logs := make([]types.Log, 0)
+	for number := from; number <= to; number++ {
+		log := types.Log{
+			Address:     common.HexToAddress(testNftAddress),
+			BlockNumber: number,
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(number)),
+			TxHash:      common.BigToHash(new(big.Int).SetUint64(number + 1000)),
+		}
+		if number%2 == 0 {
+			// erc20, the value is the data
+			log.Topics = []common.Hash{transfer, from32, to32}
+			log.Data = common.BigToHash(new(big.Int).SetUint64(number)).Bytes()
+		} else {
+			// erc721, the token id is indexed
+			log.Topics = []common.Hash{transfer, from32, to32, common.BigToHash(new(big.Int).SetUint64(number))}
+			log.Data = []byte{}
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func TestFilterLogs(t *testing.T) {
+	stub := &logsStub{maxRange: 3, latest: 20}
+	chain := newStubChain(t, stub)
+	erc20Abi, err := abiutil.LoadSignatures("event Transfer(address indexed from, address indexed to, uint256 value)")
+	require.Nil(t, err)
+	erc721Abi, err := abiutil.LoadSignatures("event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)")
+	require.Nil(t, err)
+
+	logs, err := chain.FilterLogsCtx(context.Background(), LogQuery{
+		Addresses:  []string{testNftAddress},
+		Topics:     [][]string{{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"}},
+		FromBlock:  5,
+		BlockRange: 10,
+	}, erc20Abi, erc721Abi)
+	require.Nil(t, err)
+	require.Len(t, logs, 16)
+	for i, log := range logs {
+		number := uint64(i + 5)
+		require.Equal(t, number, log.BlockNumber)
+		require.Equal(t, "Transfer", log.Event)
+		require.Equal(t, common.HexToAddress(testNonceAddress), log.Fields["from"])
+		if number%2 == 0 {
+			require.Equal(t, number, log.Fields["value"].(*big.Int).Uint64())
+		} else {
+			require.Equal(t, number, log.Fields["tokenId"].(*big.Int).Uint64())
+		}
+	}
+	// the parts of 10 blocks are split until the node accepts them
+	require.Equal(t, [2]uint64{5, 14}, stub.ranges[0])
+	require.Equal(t, [2]uint64{5, 9}, stub.ranges[1])
+	require.Equal(t, [2]uint64{5, 7}, stub.ranges[2])
+
+	// no abi, the logs are not decoded
+	logs, err = chain.FilterLogsCtx(context.Background(), LogQuery{FromBlock: 1, ToBlock: 2})
+	require.Nil(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, "", logs[0].Event)
+	require.Len(t, logs[0].Topics, 4)
+
+	// the rate limit is not split
+	stub.lock.Lock()
+	stub.limited = true
+	stub.ranges = nil
+	stub.lock.Unlock()
+	_, err = chain.FilterLogsCtx(context.Background(), LogQuery{FromBlock: 1, ToBlock: 10})
+	require.NotNil(t, err)
+	require.Len(t, stub.ranges, 1)
+
+	_, err = chain.FilterLogsCtx(context.Background(), LogQuery{FromBlock: 3, ToBlock: 2})
+	require.NotNil(t, err)
+	_, err = chain.FilterLogsCtx(context.Background(), LogQuery{Topics: [][]string{{"0x1234"}}})
+	require.NotNil(t, err)
+}
+
+func TestIsLogsLimitError(t *testing.T) {
+	require.True(t, IsLogsLimitError(errors.New("query returned more than 10000 results")))
+	require.True(t, IsLogsLimitError(errors.New("exceed maximum block range: 5000")))
+	require.True(t, IsLogsLimitError(errors.New("Log response size exceeded.")))
+	require.False(t, IsLogsLimitError(rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Body: []byte("limit exceeded")}))
+	require.False(t, IsLogsLimitError(errors.New("rate limit exceeded")))
+	require.False(t, IsLogsLimitError(errors.New("Too Many Requests")))
+	require.False(t, IsLogsLimitError(nil))
+}
@@ -0,0 +1,279 @@
+package model
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+type MnemonicLanguage string
+
+const (
+	MnemonicEnglish            MnemonicLanguage = "english"
+	MnemonicChineseSimplified  MnemonicLanguage = "chinese_simplified"
+	MnemonicChineseTraditional MnemonicLanguage = "chinese_traditional"
+	MnemonicJapanese           MnemonicLanguage = "japanese"
+	MnemonicKorean             MnemonicLanguage = "korean"
+	MnemonicSpanish            MnemonicLanguage = "spanish"
+	MnemonicFrench             MnemonicLanguage = "french"
+	MnemonicItalian            MnemonicLanguage = "italian"
+	MnemonicCzech              MnemonicLanguage = "czech"
+)
+
+// mnemonicLanguages the order is also the priority when detect the language of a mnemonic
+var mnemonicLanguages = []MnemonicLanguage{
+	MnemonicEnglish,
+	MnemonicChineseSimplified,
+	MnemonicChineseTraditional,
+	MnemonicJapanese,
+	MnemonicKorean,
+	MnemonicSpanish,
+	MnemonicFrench,
+	MnemonicItalian,
+	MnemonicCzech,
+}
+
+var (
+	ErrMnemonicWordCount   = errors.New("invalid mnemonic word count")
+	ErrMnemonicUnknownWord = errors.New("mnemonic word is not in the wordlist")
+	ErrMnemonicChecksum    = errors.New("invalid mnemonic checksum")
+	ErrMnemonicLanguage    = errors.New("unsupported mnemonic language")
+)
+
+// MnemonicError
+//
+//	@Description: the detail of an invalid mnemonic, use errors.Is with ErrMnemonicXXX to check the reason
+type MnemonicError struct {
+	Err      error
+	Language MnemonicLanguage
+	Index    int // index of the bad word, -1 if the error is not about one word
+	Word     string
+}
+
+func (e *MnemonicError) Error() string {
+	if e.Index >= 0 {
+		return fmt.Sprintf("%s: word %d %q", e.Err.Error(), e.Index+1, e.Word)
+	}
+	return e.Err.Error()
+}
+
+func (e *MnemonicError) Unwrap() error {
+	return e.Err
+}
+
+type wordlist struct {
+	words []string
+	index map[string]int
+}
+
+var wordlistCache = make(map[MnemonicLanguage]*wordlist)
+var wordlistLock sync.Mutex
+
+// getWordlist
+//
+//	@Description: the index is keyed by NFKD normalized words, which is required by bip39
+//	@param language
+//	@return *wordlist
+//	@return error
+func getWordlist(language MnemonicLanguage) (*wordlist, error) {
+	wordlistLock.Lock()
+	defer wordlistLock.Unlock()
+
+	if list, ok := wordlistCache[language]; ok {
+		return list, nil
+	}
+
+	var words []string
+	switch language {
+	case MnemonicEnglish:
+		words = wordlists.English
+	case MnemonicChineseSimplified:
+		words = wordlists.ChineseSimplified
+	case MnemonicChineseTraditional:
+		words = wordlists.ChineseTraditional
+	case MnemonicJapanese:
+		words = wordlists.Japanese
+	case MnemonicKorean:
+		words = wordlists.Korean
+	case MnemonicSpanish:
+		words = wordlists.Spanish
+	case MnemonicFrench:
+		words = wordlists.French
+	case MnemonicItalian:
+		words = wordlists.Italian
+	case MnemonicCzech:
+		words = wordlists.Czech
+	default:
+		return nil, &MnemonicError{Err: ErrMnemonicLanguage, Language: language, Index: -1}
+	}
+
+	list := &wordlist{
+		words: words,
+		index: make(map[string]int, len(words)),
+	}
+	for i, word := range words {
+		list.index[norm.NFKD.String(word)] = i
+	}
+	wordlistCache[language] = list
+	return list, nil
+}
+
+// NewMnemonic
+//
+//	@Description: generate a mnemonic
+//	@param wordCount 12, 15, 18, 21 or 24
+//	@param language
+//	@return string
+//	@return error
+func NewMnemonic(wordCount int, language MnemonicLanguage) (string, error) {
+	if wordCount < 12 || wordCount > 24 || wordCount%3 != 0 {
+		return "", &MnemonicError{Err: ErrMnemonicWordCount, Language: language, Index: -1}
+	}
+	list, err := getWordlist(language)
+	if err != nil {
+		return "", err
+	}
+
+	entropy, err := bip39.NewEntropy(wordCount / 3 * 32)
+	if err != nil {
+		return "", err
+	}
+
+	// entropy + checksum, every 11 bits is the index of one word
+	checksumBits := uint(len(entropy) / 4)
+	hash := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, checksumBits)
+	data.Or(data, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	words := make([]string, wordCount)
+	mask := big.NewInt(2047)
+	for i := wordCount - 1; i >= 0; i-- {
+		words[i] = list.words[new(big.Int).And(data, mask).Int64()]
+		data.Rsh(data, 11)
+	}
+
+	separator := " "
+	if language == MnemonicJapanese {
+		// bip39 uses ideographic space for japanese
+		separator = "　"
+	}
+	return strings.Join(words, separator), nil
+}
+
+// ValidateMnemonic
+//
+//	@Description: check the words and the checksum of a mnemonic
+//	@param mnemonic
+//	@param language if it is empty, the language will be detected by the words
+//	@return error *MnemonicError
+func ValidateMnemonic(mnemonic string, language MnemonicLanguage) error {
+	if language != "" {
+		return validateMnemonic(mnemonic, language)
+	}
+	_, err := DetectMnemonicLanguage(mnemonic)
+	return err
+}
+
+// DetectMnemonicLanguage
+//
+//	@Description: find the language of a valid mnemonic
+//	@param mnemonic
+//	@return MnemonicLanguage
+//	@return error *MnemonicError
+func DetectMnemonicLanguage(mnemonic string) (MnemonicLanguage, error) {
+	words := mnemonicWords(mnemonic)
+	if len(words) == 0 {
+		return "", &MnemonicError{Err: ErrMnemonicWordCount, Index: -1}
+	}
+
+	// some wordlists share words (eg: chinese), so every language matching all words is checked
+	var firstErr error
+	bestLanguage := MnemonicEnglish
+	bestMatched := -1
+	for _, language := range mnemonicLanguages {
+		list, err := getWordlist(language)
+		if err != nil {
+			return "", err
+		}
+		matched := 0
+		for _, word := range words {
+			if _, ok := list.index[word]; ok {
+				matched++
+			}
+		}
+		if matched > bestMatched {
+			bestLanguage, bestMatched = language, matched
+		}
+		if matched != len(words) {
+			continue
+		}
+		err = validateMnemonic(mnemonic, language)
+		if err == nil {
+			return language, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return "", firstErr
+	}
+	// report the unknown word with the most likely language
+	return "", validateMnemonic(mnemonic, bestLanguage)
+}
+
+func validateMnemonic(mnemonic string, language MnemonicLanguage) error {
+	list, err := getWordlist(language)
+	if err != nil {
+		return err
+	}
+
+	words := mnemonicWords(mnemonic)
+	for i, word := range words {
+		if _, ok := list.index[word]; !ok {
+			return &MnemonicError{Err: ErrMnemonicUnknownWord, Language: language, Index: i, Word: word}
+		}
+	}
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return &MnemonicError{Err: ErrMnemonicWordCount, Language: language, Index: -1}
+	}
+
+	data := new(big.Int)
+	for _, word := range words {
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(list.index[word])))
+	}
+
+	checksumBits := uint(len(words) / 3)
+	checksum := new(big.Int).And(data, big.NewInt(int64(1)<<checksumBits-1))
+	data.Rsh(data, checksumBits)
+
+	entropy := make([]byte, len(words)/3*4)
+	data.FillBytes(entropy)
+	hash := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(hash[0]>>(8-checksumBits)) {
+		return &MnemonicError{Err: ErrMnemonicChecksum, Language: language, Index: -1}
+	}
+	return nil
+}
+
+func mnemonicWords(mnemonic string) []string {
+	return strings.Fields(norm.NFKD.String(mnemonic))
+}
+
+// mnemonicSeed
+//
+//	@Description: bip39 seed, mnemonic and passphrase are normalized by NFKD
+//	@param mnemonic
+//	@param passphrase
+//	@return []byte
+func mnemonicSeed(mnemonic, passphrase string) []byte {
+	return bip39.NewSeed(strings.Join(mnemonicWords(mnemonic), " "), norm.NFKD.String(passphrase))
+}
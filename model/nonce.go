@@ -0,0 +1,267 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NonceManager
+//
+//	@Description: hand out the nonces of the local accounts, so many txs of one account can be sent concurrently.
+//	the nonce of an account is loaded from the node once, then counted locally and reconciled with the node every config.NonceResyncInterval
+type NonceManager struct {
+	chain    *Chain
+	key      string // the registry key if it is shared by the connects of the key
+	lock     sync.Mutex
+	accounts map[common.Address]*accountNonce
+}
+
+type accountNonce struct {
+	lock     sync.Mutex
+	synced   bool
+	syncedAt time.Time
+	next     uint64              // the next new nonce
+	gaps     []uint64            // the nonces released by failed sends, sorted, reused first
+	inflight map[uint64]struct{} // the nonces handed out, not released or sent yet
+}
+
+func NewNonceManager(chain *Chain) *NonceManager {
+	return &NonceManager{
+		chain:    chain,
+		accounts: make(map[common.Address]*accountNonce),
+	}
+}
+
+func (m *NonceManager) account(address string) *accountNonce {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	addr := common.HexToAddress(address)
+	account, ok := m.accounts[addr]
+	if !ok {
+		account = &accountNonce{inflight: make(map[uint64]struct{})}
+		m.accounts[addr] = account
+	}
+	return account
+}
+
+// Next
+//
+//	@Description: get a nonce for a new tx, the gaps left by failed sends are used first.
+//	call Release if the tx is surely not sent, otherwise Sent
+//	@receiver m
+//	@param ctx
+//	@param address
+//	@return uint64
+//	@return error the nonce can't be loaded from the node
+func (m *NonceManager) Next(ctx context.Context, address string) (uint64, error) {
+	account := m.account(address)
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	if !account.synced || time.Since(account.syncedAt) > time.Duration(config.NonceResyncInterval)*time.Second {
+		if err := m.resync(ctx, account, address); err != nil {
+			return 0, err
+		}
+	}
+
+	var nonce uint64
+	if len(account.gaps) > 0 {
+		nonce = account.gaps[0]
+		account.gaps = account.gaps[1:]
+	} else {
+		nonce = account.next
+		account.next++
+	}
+	account.inflight[nonce] = struct{}{}
+	return nonce, nil
+}
+
+// Sent
+//
+//	@Description: the tx of the nonce is sent, or the send failed but the tx may have been sent, eg: timeouts.
+//	if the node never got it, the next Resync hands it out again
+//	@receiver m
+//	@param address
+//	@param nonce
+func (m *NonceManager) Sent(address string, nonce uint64) {
+	account := m.account(address)
+	account.lock.Lock()
+	defer account.lock.Unlock()
+	delete(account.inflight, nonce)
+}
+
+// Release
+//
+//	@Description: give back a nonce which is not used by any tx, eg: the send failed
+//	@receiver m
+//	@param address
+//	@param nonce
+func (m *NonceManager) Release(address string, nonce uint64) {
+	account := m.account(address)
+	account.lock.Lock()
+	defer account.lock.Unlock()
+
+	delete(account.inflight, nonce)
+	if !account.synced || nonce >= account.next {
+		return
+	}
+	account.addGap(nonce)
+}
+
+// Resync
+//
+//	@Description: reconcile the local nonce with the pending nonce of the node, eg: after "nonce too low", "nonce too high",
+//	or a send timed out. the pending nonce of the node is the first nonce it doesn't have, if it is lower than the local one
+//	and not being sent, the tx of it was never received or was dropped, it is handed out again.
+//	the nonces being sent are never repeated
+//	@receiver m
+//	@param ctx
+//	@param address
+//	@return error
+func (m *NonceManager) Resync(ctx context.Context, address string) error {
+	account := m.account(address)
+	account.lock.Lock()
+	defer account.lock.Unlock()
+	return m.resync(ctx, account, address)
+}
+
+func (m *NonceManager) resync(ctx context.Context, account *accountNonce, address string) error {
+	nonce, err := m.nodeChain().NonceCtx(ctx, address)
+	if err != nil {
+		return err
+	}
+	synced := account.synced
+	account.synced = true
+	account.syncedAt = time.Now()
+
+	// the nonces below the node nonce were used
+	for inflight := range account.inflight {
+		if inflight < nonce {
+			delete(account.inflight, inflight)
+		}
+	}
+	gaps := account.gaps[:0]
+	for _, gap := range account.gaps {
+		if gap >= nonce {
+			gaps = append(gaps, gap)
+		}
+	}
+	account.gaps = gaps
+
+	if !synced || nonce >= account.next {
+		account.next = nonce
+		account.gaps = nil
+		return nil
+	}
+	// the node doesn't have the nonce, all later txs wait behind it
+	if _, ok := account.inflight[nonce]; !ok {
+		account.addGap(nonce)
+	}
+	return nil
+}
+
+// nodeChain
+//
+//	@Description: the chain to load the nonce, the cached connect of the key if it is shared, so an evicted connect is not reconnected by it
+//	@receiver m
+//	@return *Chain
+func (m *NonceManager) nodeChain() *Chain {
+	if m.key != "" {
+		lock.RLock()
+		chain, ok := chainConnections[m.key]
+		lock.RUnlock()
+		if ok {
+			return chain
+		}
+	}
+	return m.chain
+}
+
+// addGap
+//
+//	@Description: add a nonce below next to the gaps, the gaps at the end are not gaps any more
+//	@receiver a
+//	@param nonce
+func (a *accountNonce) addGap(nonce uint64) {
+	for _, gap := range a.gaps {
+		if gap == nonce {
+			return
+		}
+	}
+	a.gaps = append(a.gaps, nonce)
+	sort.Slice(a.gaps, func(i, j int) bool {
+		return a.gaps[i] < a.gaps[j]
+	})
+
+	for len(a.gaps) > 0 && a.gaps[len(a.gaps)-1] == a.next-1 {
+		a.gaps = a.gaps[:len(a.gaps)-1]
+		a.next--
+	}
+}
+
+// Reset
+//
+//	@Description: forget the local nonce of the account, it will be loaded from the node at the next time
+//	@receiver m
+//	@param address
+func (m *NonceManager) Reset(address string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.accounts, common.HexToAddress(address))
+}
+
+// IsNonceError
+//
+//	@Description: the send failed because the nonce has been used
+//	@param err
+//	@return bool
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "known transaction") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// IsNonceTooHighError
+//
+//	@Description: the node rejected the tx because a lower nonce of the account is missing
+//	@param err
+//	@return bool
+func IsNonceTooHighError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too high")
+}
+
+// IsAlreadyKnownError
+//
+//	@Description: the same tx is already in the pool of the node
+//	@param err
+//	@return bool
+func IsAlreadyKnownError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
+
+// isRejectedError
+//
+//	@Description: the node answered the send with an error, so the tx is surely not in its pool.
+//	the transport errors and the timeouts are not, the tx may have been sent
+//	@param err
+//	@return bool
+func isRejectedError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && !IsNonceError(err)
+}
@@ -0,0 +1,264 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNonceAddress = "0x7a547A149A79A03F4dd441B6806ffCBb1b63F383"
+
+// ethStub
+//
+//	@Description: a local stub of the "eth" namespace, only the methods used by the tests
+type ethStub struct {
+	lock        sync.Mutex
+	chainId     int64 // 1 if it is 0
+	nonce       uint64
+	blockNumber uint64
+	down        bool
+}
+
+func (e *ethStub) ChainId() (hexutil.Big, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.down {
+		return hexutil.Big{}, errors.New("node is down")
+	}
+	if e.chainId == 0 {
+		return hexutil.Big(*common.Big1), nil
+	}
+	return hexutil.Big(*big.NewInt(e.chainId)), nil
+}
+
+func (e *ethStub) BlockNumber() (hexutil.Uint64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.down {
+		return 0, errors.New("node is down")
+	}
+	return hexutil.Uint64(e.blockNumber), nil
+}
+
+func (e *ethStub) GetTransactionCount(_ context.Context, _ common.Address, _ rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return hexutil.Uint64(e.nonce), nil
+}
+
+func (e *ethStub) setNonce(nonce uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.nonce = nonce
+}
+
+func newStubClient(t *testing.T, service interface{}) *rpc.Client {
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	require.Nil(t, server.RegisterName("eth", service))
+	return rpc.DialInProc(server)
+}
+
+func newStubChain(t *testing.T, service interface{}) *Chain {
+	chain, err := newChainFromClient(context.Background(), newStubClient(t, service), "inproc", 10)
+	require.Nil(t, err)
+	return chain
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	stub := &ethStub{nonce: 5}
+	manager := newStubChain(t, stub).NonceManager()
+
+	var lock sync.Mutex
+	nonces := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := manager.Next(context.Background(), testNonceAddress)
+			require.Nil(t, err)
+			lock.Lock()
+			defer lock.Unlock()
+			require.False(t, nonces[nonce], "nonce %d is repeated", nonce)
+			nonces[nonce] = true
+		}()
+	}
+	wg.Wait()
+	for nonce := uint64(5); nonce < 55; nonce++ {
+		require.True(t, nonces[nonce], "nonce %d is missing", nonce)
+	}
+}
+
+func TestNonceManagerGaps(t *testing.T) {
+	ctx := context.Background()
+	stub := &ethStub{nonce: 10}
+	manager := newStubChain(t, stub).NonceManager()
+
+	for i := uint64(10); i < 14; i++ {
+		nonce, err := manager.Next(ctx, testNonceAddress)
+		require.Nil(t, err)
+		require.Equal(t, i, nonce)
+	}
+
+	// the gap is used first
+	manager.Release(testNonceAddress, 11)
+	nonce, err := manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(11), nonce)
+
+	// the released last nonce is handed out again without a gap
+	manager.Release(testNonceAddress, 13)
+	nonce, err = manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(13), nonce)
+
+	// other txs used the nonces, resync jumps forward and drops the used gaps
+	manager.Release(testNonceAddress, 12)
+	stub.setNonce(20)
+	require.Nil(t, manager.Resync(ctx, testNonceAddress))
+	nonce, err = manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(20), nonce)
+
+	// the node lost the nonce 15, it is handed out again, the nonce being sent is never repeated
+	manager.Sent(testNonceAddress, 20)
+	stub.setNonce(15)
+	require.Nil(t, manager.Resync(ctx, testNonceAddress))
+	nonce, err = manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(15), nonce)
+	require.Nil(t, manager.Resync(ctx, testNonceAddress))
+	nonce, err = manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(21), nonce)
+
+	manager.Reset(testNonceAddress)
+	nonce, err = manager.Next(ctx, testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(15), nonce)
+}
+
+// sendStub
+//
+//	@Description: the node rejects the raw tx, or answers it after the caller's timeout
+type sendStub struct {
+	ethStub
+	reject bool
+	delay  time.Duration
+}
+
+func (s *sendStub) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	if s.reject {
+		return common.Hash{}, errors.New("insufficient funds for gas * price + value")
+	}
+	time.Sleep(s.delay)
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+func TestNonceReleasedOnlyIfRejected(t *testing.T) {
+	stub := &sendStub{ethStub: ethStub{nonce: 3}, reject: true}
+	chain := newStubChain(t, stub)
+	token := NewToken(chain)
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	address := s.Address().Hex()
+
+	// the node rejected it, the nonce is handed out again
+	_, err = token.TransferWithSignerCtx(context.Background(), s, "", "1000000000", "21000", "", "1", testNonceAddress, "")
+	require.NotNil(t, err)
+	nonce, err := chain.NonceManager().Next(context.Background(), address)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+	chain.NonceManager().Release(address, nonce)
+
+	// the tx may have been sent before the timeout, the nonce is kept
+	stub.reject = false
+	stub.delay = 500 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = token.TransferWithSignerCtx(ctx, s, "", "1000000000", "21000", "", "1", testNonceAddress, "")
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	nonce, err = chain.NonceManager().Next(context.Background(), address)
+	require.Nil(t, err)
+	require.Equal(t, uint64(4), nonce)
+}
+
+func TestNonceReusedAfterTimeout(t *testing.T) {
+	stub := &sendStub{ethStub: ethStub{nonce: 7}, delay: 500 * time.Millisecond}
+	chain := newStubChain(t, stub)
+	token := NewToken(chain)
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	address := s.Address().Hex()
+
+	// the send timed out and the node never got the tx
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = token.TransferWithSignerCtx(ctx, s, "", "1000000000", "21000", "", "1", testNonceAddress, "")
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the pending nonce of the node is still 7, it is not lost
+	require.Nil(t, chain.NonceManager().Resync(context.Background(), address))
+	nonce, err := chain.NonceManager().Next(context.Background(), address)
+	require.Nil(t, err)
+	require.Equal(t, uint64(7), nonce)
+}
+
+func TestNonceTooHigh(t *testing.T) {
+	stub := &nonceTooHighStub{sendStub: sendStub{ethStub: ethStub{nonce: 2}}}
+	chain := newStubChain(t, stub)
+	token := NewToken(chain)
+	s, err := signer.NewPrivateKeySigner("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	address := s.Address().Hex()
+
+	// the nonces 2 and 3 are handed out, the tx of 2 never reached the node
+	nonce, err := chain.NonceManager().Next(context.Background(), address)
+	require.Nil(t, err)
+	chain.NonceManager().Sent(address, nonce)
+
+	// 3 is rejected as too high, the send is retried with 2
+	_, err = token.TransferWithSignerCtx(context.Background(), s, "", "1000000000", "21000", "", "1", testNonceAddress, "")
+	require.Nil(t, err)
+	require.Equal(t, []uint64{3, 2}, stub.sent)
+	nonce, err = chain.NonceManager().Next(context.Background(), address)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+}
+
+// nonceTooHighStub
+//
+//	@Description: the node rejects the txs above its pending nonce
+type nonceTooHighStub struct {
+	sendStub
+	sent []uint64
+}
+
+func (s *nonceTooHighStub) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	s.sent = append(s.sent, tx.Nonce())
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if tx.Nonce() > s.nonce {
+		return common.Hash{}, errors.New("nonce too high")
+	}
+	s.nonce = tx.Nonce() + 1
+	return tx.Hash(), nil
+}
@@ -0,0 +1,659 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RpcStrategy int
+
+const (
+	RpcStrategyFailover     RpcStrategy = iota // the first healthy endpoint in the order of the urls
+	RpcStrategyRoundRobin                      // the healthy endpoints in turn
+	RpcStrategyLeastLatency                    // the healthy endpoint with the lowest latency
+)
+
+// RpcPoolOptions
+//
+//	@Description: the zero values are replaced by the defaults in config
+type RpcPoolOptions struct {
+	Strategy            RpcStrategy
+	HealthCheckInterval time.Duration
+	MaxBlockLag         uint64        // behind the highest endpoint more than it is unhealthy
+	MaxLatency          time.Duration // 0 means no limit
+	MaxErrorRate        float64       // error rate of the recent health checks
+}
+
+func (o RpcPoolOptions) withDefaults() RpcPoolOptions {
+	if o.HealthCheckInterval <= 0 {
+		o.HealthCheckInterval = time.Duration(config.RpcHealthCheckInterval) * time.Second
+	}
+	if o.MaxBlockLag == 0 {
+		o.MaxBlockLag = config.RpcMaxBlockLag
+	}
+	if o.MaxErrorRate <= 0 {
+		o.MaxErrorRate = config.RpcMaxErrorRate
+	}
+	return o
+}
+
+// RpcEndpointStatus
+//
+//	@Description: the state of one endpoint after the last health check
+type RpcEndpointStatus struct {
+	Url         string
+	Healthy     bool
+	BlockNumber uint64
+	Latency     time.Duration
+	ErrorRate   float64
+	Err         error
+}
+
+type rpcEndpoint struct {
+	url       string
+	httpUrl   *url.URL // nil if it is not a http url
+	rpcClient *rpc.Client
+	client    *ethclient.Client
+	verified  bool // the chain id has been checked
+	redial    bool // the connect is broken, dial again at the next check
+
+	healthy     bool
+	blockNumber uint64
+	latency     time.Duration
+	results     []bool // recent health check results, true is error
+	err         error
+}
+
+func (e *rpcEndpoint) errorRate() float64 {
+	if len(e.results) == 0 {
+		return 0
+	}
+	failed := 0
+	for _, result := range e.results {
+		if result {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(e.results))
+}
+
+func (e *rpcEndpoint) record(err error) {
+	e.err = err
+	e.results = append(e.results, err != nil)
+	if len(e.results) > config.RpcErrorWindow {
+		e.results = e.results[len(e.results)-config.RpcErrorWindow:]
+	}
+}
+
+// rpcPool
+//
+//	@Description: many endpoints of the same chain, the calls go to the healthy ones
+type rpcPool struct {
+	chainId  *big.Int
+	timeout  int64
+	options  RpcPoolOptions
+	lock     sync.RWMutex
+	eps      []*rpcEndpoint
+	next     uint64
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// failover the client of all http endpoints, a read call is sent to the next healthy endpoint if the chosen one is unreachable,
+	// nil if any url is not http, then the endpoint is only switched by the health check
+	failover       *rpc.Client
+	failoverClient *ethclient.Client
+}
+
+// newChainWithPool
+//
+//	@Description: dial all urls, every reachable endpoint must report the same chain id.
+//	the unreachable endpoints are checked again by the health check
+//	@param ctx
+//	@param rpcUrls
+//	@param timeout
+//	@param options
+//	@return *Chain
+//	@return error
+func newChainWithPool(ctx context.Context, rpcUrls []string, timeout int64, options RpcPoolOptions) (*Chain, error) {
+	if timeout <= 0 {
+		timeout = 60
+	}
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
+	clients := make([]*rpc.Client, len(rpcUrls))
+	for i, rpcUrl := range rpcUrls {
+		// the unreachable endpoint is dialed again by the health check
+		clients[i], _ = rpc.DialContext(ctx, rpcUrl)
+	}
+	return newChainWithPoolClients(ctx, rpcUrls, clients, timeout, options)
+}
+
+func newChainWithPoolClients(ctx context.Context, rpcUrls []string, clients []*rpc.Client, timeout int64, options RpcPoolOptions) (*Chain, error) {
+	pool := &rpcPool{
+		timeout: timeout,
+		options: options.withDefaults(),
+		stop:    make(chan struct{}),
+	}
+	for i, rpcUrl := range rpcUrls {
+		ep := &rpcEndpoint{url: rpcUrl, rpcClient: clients[i]}
+		if isHttpUrl(rpcUrl) {
+			ep.httpUrl, _ = url.Parse(rpcUrl)
+		}
+		pool.eps = append(pool.eps, ep)
+	}
+
+	pool.check(ctx)
+	if pool.chainId == nil {
+		pool.close()
+		return nil, fmt.Errorf("no rpc endpoint is reachable: %w", pool.eps[0].err)
+	}
+	for _, ep := range pool.eps {
+		if errors.Is(ep.err, errChainIdMismatch) {
+			pool.close()
+			return nil, fmt.Errorf("%s: %w", ep.url, ep.err)
+		}
+	}
+	err := pool.dialFailover(ctx)
+	if err != nil {
+		pool.close()
+		return nil, err
+	}
+	chain := &Chain{
+		ChainId:     pool.chainId,
+		rpcUrl:      rpcUrls[0],
+		Timeout:     timeout,
+		pool:        pool,
+		rpcUrls:     rpcUrls,
+		poolOptions: options,
+		lastUsed:    time.Now().UnixNano(),
+	}
+	chain.RemoteRpcClient, err = newRelayClient(chain)
+	if err != nil {
+		pool.close()
+		return nil, err
+	}
+	go pool.loop()
+	return chain, nil
+}
+
+var errChainIdMismatch = errors.New("the chain id of the rpc endpoint is different")
+
+func (p *rpcPool) loop() {
+	ticker := time.NewTicker(p.options.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.check(context.Background())
+		}
+	}
+}
+
+// check
+//
+//	@Description: one round of the health check, block height lag, latency and error rate
+//	@receiver p
+//	@param ctx
+func (p *rpcPool) check(ctx context.Context) {
+	type result struct {
+		rpcClient   *rpc.Client
+		chainId     *big.Int
+		blockNumber uint64
+		latency     time.Duration
+		err         error
+	}
+
+	p.lock.RLock()
+	eps := append([]*rpcEndpoint(nil), p.eps...)
+	p.lock.RUnlock()
+
+	results := make([]result, len(eps))
+	var wg sync.WaitGroup
+	for i, ep := range eps {
+		wg.Add(1)
+		go func(i int, ep *rpcEndpoint) {
+			defer wg.Done()
+			callCtx, cancel := withTimeout(ctx, p.timeout)
+			defer cancel()
+
+			p.lock.RLock()
+			rpcClient, verified, redial := ep.rpcClient, ep.verified, ep.redial
+			p.lock.RUnlock()
+			r := &results[i]
+			if rpcClient == nil || redial {
+				if rpcClient, r.err = rpc.DialContext(callCtx, ep.url); r.err != nil {
+					return
+				}
+				r.rpcClient = rpcClient
+			}
+			client := ethclient.NewClient(rpcClient)
+			if !verified {
+				if r.chainId, r.err = client.ChainID(callCtx); r.err != nil {
+					return
+				}
+			}
+			start := time.Now()
+			r.blockNumber, r.err = client.BlockNumber(callCtx)
+			r.latency = time.Since(start)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var highest uint64
+	for i, ep := range eps {
+		r := results[i]
+		if r.rpcClient != nil {
+			// the old client is kept until the new one is dialed, so the callers never get a nil client
+			if ep.rpcClient != nil {
+				ep.rpcClient.Close()
+			}
+			ep.rpcClient = r.rpcClient
+			ep.client = nil
+			ep.redial = false
+		}
+		if isTransportError(r.err) {
+			ep.redial = true
+		}
+		if ep.rpcClient != nil && ep.client == nil {
+			ep.client = ethclient.NewClient(ep.rpcClient)
+		}
+		if r.chainId != nil {
+			if p.chainId == nil {
+				p.chainId = r.chainId
+			}
+			if r.chainId.Cmp(p.chainId) != 0 {
+				r.err = errChainIdMismatch
+			} else {
+				ep.verified = true
+			}
+		}
+		ep.record(r.err)
+		if r.err == nil {
+			ep.blockNumber = r.blockNumber
+			ep.latency = r.latency
+			if r.blockNumber > highest {
+				highest = r.blockNumber
+			}
+		}
+	}
+	for _, ep := range eps {
+		ep.healthy = ep.verified && ep.err == nil &&
+			highest-ep.blockNumber <= p.options.MaxBlockLag &&
+			ep.errorRate() <= p.options.MaxErrorRate &&
+			(p.options.MaxLatency <= 0 || ep.latency <= p.options.MaxLatency)
+	}
+}
+
+// endpoint
+//
+//	@Description: choose an endpoint by the strategy, if none is healthy, the first connected one is used
+//	@receiver p
+//	@return *rpcEndpoint
+func (p *rpcPool) endpoint() *rpcEndpoint {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	var healthy []*rpcEndpoint
+	for _, ep := range p.eps {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		for _, ep := range p.eps {
+			if ep.client != nil {
+				return ep
+			}
+		}
+		return p.eps[0]
+	}
+
+	switch p.options.Strategy {
+	case RpcStrategyRoundRobin:
+		return healthy[atomic.AddUint64(&p.next, 1)%uint64(len(healthy))]
+	case RpcStrategyLeastLatency:
+		best := healthy[0]
+		for _, ep := range healthy[1:] {
+			if ep.latency < best.latency {
+				best = ep
+			}
+		}
+		return best
+	default:
+		return healthy[0]
+	}
+}
+
+// clients
+//
+//	@Description: the failover clients, or the clients of the chosen endpoint, they are read under the lock because the health check may replace them
+//	@receiver p
+//	@return *ethclient.Client
+//	@return *rpc.Client
+func (p *rpcPool) clients() (*ethclient.Client, *rpc.Client) {
+	if p.failover != nil {
+		return p.failoverClient, p.failover
+	}
+	ep := p.endpoint()
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return ep.client, ep.rpcClient
+}
+
+func (p *rpcPool) status() []RpcEndpointStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	status := make([]RpcEndpointStatus, len(p.eps))
+	for i, ep := range p.eps {
+		status[i] = RpcEndpointStatus{
+			Url:         ep.url,
+			Healthy:     ep.healthy,
+			BlockNumber: ep.blockNumber,
+			Latency:     ep.latency,
+			ErrorRate:   ep.errorRate(),
+			Err:         ep.err,
+		}
+	}
+	return status
+}
+
+func (p *rpcPool) close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, ep := range p.eps {
+		if ep.rpcClient != nil {
+			ep.rpcClient.Close()
+		}
+	}
+	if p.failover != nil {
+		p.failover.Close()
+	}
+}
+
+// dialFailover
+//
+//	@Description: create the failover client if all urls are http, the requests are routed by failoverTransport
+//	@receiver p
+//	@param ctx
+//	@return error
+func (p *rpcPool) dialFailover(ctx context.Context) error {
+	for _, ep := range p.eps {
+		if ep.httpUrl == nil {
+			return nil
+		}
+	}
+	client, err := rpc.DialOptions(ctx, p.eps[0].url, rpc.WithHTTPClient(&http.Client{Transport: &failoverTransport{pool: p}}))
+	if err != nil {
+		return err
+	}
+	p.failover = client
+	p.failoverClient = ethclient.NewClient(client)
+	return nil
+}
+
+// candidates
+//
+//	@Description: the chosen endpoint first, then the other healthy ones in the order of the urls
+//	@receiver p
+//	@return []*rpcEndpoint
+func (p *rpcPool) candidates() []*rpcEndpoint {
+	first := p.endpoint()
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	eps := []*rpcEndpoint{first}
+	for _, ep := range p.eps {
+		if ep != first && ep.healthy {
+			eps = append(eps, ep)
+		}
+	}
+	return eps
+}
+
+// markDown
+//
+//	@Description: the endpoint is unreachable, it is skipped until the next health check finds it healthy
+//	@receiver p
+//	@param ep
+//	@param err
+func (p *rpcPool) markDown(ep *rpcEndpoint, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ep.healthy = false
+	ep.err = err
+}
+
+// failoverTransport
+//
+//	@Description: send the json rpc request to the chosen endpoint, the reads are sent again to the next healthy endpoint on a transport error.
+//	the errors answered by the node are not retried, and the writes are never sent twice
+type failoverTransport struct {
+	pool *rpcPool
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	retry := isReadRequest(body)
+
+	var lastErr error
+	for i, ep := range t.pool.candidates() {
+		if i > 0 && !retry {
+			break
+		}
+		r := req.Clone(req.Context())
+		r.URL, r.Host = ep.httpUrl, ""
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		if req.URL.User != nil {
+			// the basic auth of the first url is set by the http client
+			r.Header.Del("Authorization")
+		}
+		if ep.httpUrl.User != nil {
+			password, _ := ep.httpUrl.User.Password()
+			r.SetBasicAuth(ep.httpUrl.User.Username(), password)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+		t.pool.markDown(ep, err)
+	}
+	return nil, lastErr
+}
+
+// isReadRequest
+//
+//	@Description: all calls of the request, or the batch, only read the chain, they can be sent again to another node
+//	@param body
+//	@return bool
+func isReadRequest(body []byte) bool {
+	var calls []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &calls); err != nil {
+		calls = calls[:0]
+		var call struct {
+			Method string `json:"method"`
+		}
+		if err = json.Unmarshal(body, &call); err != nil {
+			return false
+		}
+		calls = append(calls, call)
+	}
+	for _, call := range calls {
+		if !isReadMethod(call.Method) {
+			return false
+		}
+	}
+	return len(calls) > 0
+}
+
+func isReadMethod(method string) bool {
+	switch method {
+	case "eth_blockNumber", "eth_chainId", "eth_call", "eth_estimateGas", "eth_gasPrice", "eth_maxPriorityFeePerGas",
+		"eth_feeHistory", "eth_blobBaseFee", "eth_syncing", "net_version", "web3_clientVersion":
+		return true
+	case "eth_getFilterChanges", "eth_getFilterLogs":
+		// the filters only exist on the node which created them
+		return false
+	}
+	return strings.HasPrefix(method, "eth_get")
+}
+
+func isHttpUrl(rpcUrl string) bool {
+	u, err := url.Parse(rpcUrl)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// newRelayClient
+//
+//	@Description: the client whose calls are sent by the client which chain.Client() returns at the time,
+//	so it never goes stale when the chain reconnects or the pool switches the endpoint. it can't subscribe
+//	@param chain
+//	@return *ethclient.Client
+//	@return error
+func newRelayClient(chain *Chain) (*ethclient.Client, error) {
+	client, err := rpc.DialOptions(context.Background(), "http://relay", rpc.WithHTTPClient(&http.Client{Transport: &chainTransport{chain: chain}}))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+type relayCall struct {
+	Id     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type relayError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type relayResult struct {
+	Version string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *relayError     `json:"error,omitempty"`
+}
+
+// chainTransport
+//
+//	@Description: answer the json rpc request by the rpc client of the chain, the errors of the node are kept with their code and data,
+//	so the revert reasons can still be decoded. a transport error is returned as it is
+type chainTransport struct {
+	chain *Chain
+}
+
+func (t *chainTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var calls []relayCall
+	batch := len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+	if batch {
+		err = json.Unmarshal(body, &calls)
+	} else {
+		calls = make([]relayCall, 1)
+		err = json.Unmarshal(body, &calls[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	elems := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		args := make([]interface{}, len(call.Params))
+		for j, param := range call.Params {
+			args[j] = param
+		}
+		elems[i] = rpc.BatchElem{Method: call.Method, Args: args, Result: new(json.RawMessage)}
+	}
+	_, rpcClient := t.chain.clients()
+	if batch {
+		err = rpcClient.BatchCallContext(req.Context(), elems)
+	} else {
+		elems[0].Error = rpcClient.CallContext(req.Context(), elems[0].Result, elems[0].Method, elems[0].Args...)
+		var rpcErr rpc.Error
+		if elems[0].Error != nil && !errors.As(elems[0].Error, &rpcErr) {
+			err = elems[0].Error
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]relayResult, len(calls))
+	for i, elem := range elems {
+		results[i] = relayResult{Version: "2.0", Id: calls[i].Id}
+		if elem.Error == nil {
+			results[i].Result = *elem.Result.(*json.RawMessage)
+			if len(results[i].Result) == 0 {
+				results[i].Result = json.RawMessage("null")
+			}
+			continue
+		}
+		results[i].Error = &relayError{Code: -32603, Message: elem.Error.Error()}
+		var rpcErr rpc.Error
+		if errors.As(elem.Error, &rpcErr) {
+			results[i].Error.Code = rpcErr.ErrorCode()
+		}
+		var dataErr rpc.DataError
+		if errors.As(elem.Error, &dataErr) {
+			results[i].Error.Data = dataErr.ErrorData()
+		}
+	}
+	var resp []byte
+	if batch {
+		resp, err = json.Marshal(results)
+	} else {
+		resp, err = json.Marshal(results[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(resp)),
+		ContentLength: int64(len(resp)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func (e *ethStub) set(blockNumber uint64, down bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.blockNumber, e.down = blockNumber, down
+}
+
+func newStubPoolChain(t *testing.T, options RpcPoolOptions, stubs ...*ethStub) (*Chain, error) {
+	urls := make([]string, len(stubs))
+	clients := make([]*rpc.Client, len(stubs))
+	for i, stub := range stubs {
+		urls[i] = "inproc" + string(rune('a'+i))
+		clients[i] = newStubClient(t, stub)
+	}
+	return newChainWithPoolClients(context.Background(), urls, clients, 10, options)
+}
+
+func TestRpcPoolChainIdMismatch(t *testing.T) {
+	_, err := newStubPoolChain(t, RpcPoolOptions{}, &ethStub{blockNumber: 100}, &ethStub{chainId: 2, blockNumber: 100})
+	require.ErrorIs(t, err, errChainIdMismatch)
+}
+
+func TestRpcPoolFailover(t *testing.T) {
+	a := &ethStub{blockNumber: 100}
+	b := &ethStub{blockNumber: 90}
+	chain, err := newStubPoolChain(t, RpcPoolOptions{}, a, b)
+	require.Nil(t, err)
+	defer chain.Close()
+
+	// b is behind too many blocks
+	status := chain.Endpoints()
+	require.True(t, status[0].Healthy)
+	require.False(t, status[1].Healthy)
+	require.Equal(t, "inproca", chain.pool.endpoint().url)
+
+	// a is down, the calls go to b
+	a.set(100, true)
+	b.set(100, false)
+	chain.pool.check(context.Background())
+	require.Equal(t, "inprocb", chain.pool.endpoint().url)
+	number, err := chain.Client().BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+}
+
+func TestRpcPoolRoundRobin(t *testing.T) {
+	a := &ethStub{blockNumber: 100}
+	b := &ethStub{blockNumber: 100}
+	chain, err := newStubPoolChain(t, RpcPoolOptions{Strategy: RpcStrategyRoundRobin}, a, b)
+	require.Nil(t, err)
+	defer chain.Close()
+
+	used := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		used[chain.pool.endpoint().url]++
+	}
+	require.Equal(t, 5, used["inproca"])
+	require.Equal(t, 5, used["inprocb"])
+}
+
+// poolSendStub
+//
+//	@Description: count the raw txs sent to the endpoint
+type poolSendStub struct {
+	ethStub
+	sent atomic.Int32
+}
+
+func (p *poolSendStub) SendRawTransaction(_ hexutil.Bytes) (common.Hash, error) {
+	p.sent.Add(1)
+	return common.Hash{}, nil
+}
+
+func TestRpcPoolCallFailover(t *testing.T) {
+	servers := make([]*httptest.Server, 2)
+	stubs := []*poolSendStub{{ethStub: ethStub{blockNumber: 100}}, {ethStub: ethStub{blockNumber: 100}}}
+	urls := make([]string, len(stubs))
+	for i, stub := range stubs {
+		server := rpc.NewServer()
+		t.Cleanup(server.Stop)
+		require.Nil(t, server.RegisterName("eth", stub))
+		servers[i] = httptest.NewServer(server)
+		t.Cleanup(servers[i].Close)
+		urls[i] = servers[i].URL
+	}
+	chain, err := newChainWithPool(context.Background(), urls, 10, RpcPoolOptions{HealthCheckInterval: time.Hour})
+	require.Nil(t, err)
+	defer chain.Close()
+
+	// the first endpoint is down, the read goes to the second one before the next health check
+	servers[0].CloseClientConnections()
+	servers[0].Close()
+	number, err := chain.Client().BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+	number, err = chain.RemoteRpcClient.BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+	require.False(t, chain.Endpoints()[0].Healthy)
+
+	// the write is never sent twice
+	chain.pool.lock.Lock()
+	chain.pool.eps[0].healthy = true
+	chain.pool.lock.Unlock()
+	_, rpcClient := chain.clients()
+	err = rpcClient.CallContext(context.Background(), nil, "eth_sendRawTransaction", "0x01")
+	require.NotNil(t, err)
+	require.Equal(t, int32(0), stubs[1].sent.Load())
+}
+
+func TestRpcPoolRemoteClient(t *testing.T) {
+	a := &estimateRevertStub{ethStub: ethStub{blockNumber: 100}, data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000001"}
+	b := &estimateRevertStub{ethStub: ethStub{blockNumber: 100}, data: a.data}
+	chain, err := newChainWithPoolClients(context.Background(), []string{"inproca", "inprocb"},
+		[]*rpc.Client{newStubClient(t, a), newStubClient(t, b)}, 10, RpcPoolOptions{HealthCheckInterval: time.Hour})
+	require.Nil(t, err)
+	defer chain.Close()
+	client := chain.RemoteRpcClient
+	require.NotNil(t, client)
+
+	number, err := client.BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+	_, err = client.TransactionReceipt(context.Background(), common.Hash{})
+	require.ErrorIs(t, err, ethereum.NotFound)
+
+	// the revert data of the node is kept
+	_, err = client.EstimateGas(context.Background(), ethereum.CallMsg{})
+	var revertErr *RevertError
+	require.True(t, errors.As(WrapRevertError(err, nil), &revertErr))
+	require.Equal(t, "assert(false)", revertErr.Reason)
+
+	batch := []rpc.BatchElem{{Method: "eth_blockNumber", Result: new(hexutil.Uint64)}, {Method: "eth_estimateGas", Args: []interface{}{map[string]interface{}{}}, Result: new(hexutil.Uint64)}}
+	require.Nil(t, client.Client().BatchCallContext(context.Background(), batch))
+	require.Equal(t, hexutil.Uint64(100), *batch[0].Result.(*hexutil.Uint64))
+	require.NotNil(t, batch[1].Error)
+}
+
+func TestIsReadRequest(t *testing.T) {
+	require.True(t, isReadRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":[]}`)))
+	require.True(t, isReadRequest([]byte(`[{"method":"eth_call"},{"method":"eth_blockNumber"}]`)))
+	require.False(t, isReadRequest([]byte(`[{"method":"eth_call"},{"method":"eth_sendRawTransaction"}]`)))
+	require.False(t, isReadRequest([]byte(`{"method":"eth_getFilterChanges"}`)))
+	require.False(t, isReadRequest([]byte(`[]`)))
+}
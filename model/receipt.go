@@ -0,0 +1,237 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"time"
+)
+
+// WaitForReceipt
+//
+//	@Description: wait until the tx has enough confirmations, follow the new heads by websocket if supported, otherwise poll.
+//	if the block of the receipt is reorged out, keep waiting for the tx to be mined again
+//	@receiver t
+//	@param ctx the whole waiting is only stopped by ctx
+//	@param hash
+//	@param confirmations 0 is the same as 1, the tx is mined
+//	@return *Transaction Status is success, reverted, dropped or replaced
+//	@return error
+func (t *Transaction) WaitForReceipt(ctx context.Context, hash string, confirmations uint64) (*Transaction, error) {
+	return t.waitForReceipt(ctx, common.HexToHash(hash), nil, confirmations)
+}
+
+// WaitForSignedTx
+//
+//	@Description: the same as WaitForReceipt, but the sender and the nonce are known from the signed tx,
+//	so the replacement is found even if the node has never seen the tx, eg: the SignedTx of types.BuildTxResult
+//	@receiver t
+//	@param ctx
+//	@param signedTx
+//	@param confirmations
+//	@return *Transaction
+//	@return error
+func (t *Transaction) WaitForSignedTx(ctx context.Context, signedTx *types.Transaction, confirmations uint64) (*Transaction, error) {
+	if signedTx == nil {
+		return nil, errors.New("signed transaction can't be empty")
+	}
+	return t.waitForReceipt(ctx, signedTx.Hash(), signedTx, confirmations)
+}
+
+// waitForReceipt
+//
+//	@Description: without sentTx, the sender and the nonce are only known after the node returns the tx
+//	@receiver t
+//	@param ctx
+//	@param txHash
+//	@param sentTx can be nil
+//	@param confirmations
+//	@return *Transaction
+//	@return error
+func (t *Transaction) waitForReceipt(ctx context.Context, txHash common.Hash, sentTx *types.Transaction, confirmations uint64) (*Transaction, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	hash := txHash.Hex()
+
+	heads, stop := t.newHeads(ctx)
+	defer stop()
+
+	var (
+		from   common.Address
+		missed int
+	)
+	if sentTx != nil {
+		var err error
+		if from, err = txSender(sentTx); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		result, mined, err := t.checkReceipt(ctx, txHash, confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			return result, nil
+		}
+
+		if !mined {
+			// no receipt, the tx may be pending, dropped or replaced
+			tx, err := t.txByHash(ctx, txHash)
+			switch {
+			case err == nil:
+				missed = 0
+				if sentTx == nil {
+					sentTx = tx
+					from, _ = txSender(tx)
+				}
+			case errors.Is(err, ethereum.NotFound):
+				missed++
+				if sentTx != nil {
+					replaced, err := t.nonceUsed(ctx, from, sentTx.Nonce())
+					if err == nil && replaced {
+						return &Transaction{Hash: hash, Nonce: sentTx.Nonce(), From: from.Hex(), Status: TxStatusReplaced}, nil
+					}
+				}
+				if missed >= config.TxDroppedPolls {
+					result := &Transaction{Hash: hash, Status: TxStatusDropped}
+					if sentTx != nil {
+						result.Nonce, result.From = sentTx.Nonce(), from.Hex()
+					}
+					return result, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-heads:
+		}
+	}
+}
+
+// checkReceipt
+//
+//	@Description: check the receipt once, the temporary errors of the node are ignored and checked again later
+//	@receiver t
+//	@param ctx
+//	@param txHash
+//	@param confirmations
+//	@return result not nil if the tx has enough confirmations
+//	@return mined the receipt exists
+//	@return err ctx is done or the tx can't be parsed
+func (t *Transaction) checkReceipt(ctx context.Context, txHash common.Hash, confirmations uint64) (result *Transaction, mined bool, err error) {
+	callCtx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+
+	// the same endpoint for all calls, the endpoints of a pool may be at different heights
+	client := t.chain.Client()
+	receipt, err := client.TransactionReceipt(callCtx, txHash)
+	if err != nil {
+		return nil, false, ctx.Err()
+	}
+
+	latest, err := client.BlockNumber(callCtx)
+	if err != nil {
+		return nil, true, ctx.Err()
+	}
+	if latest < receipt.BlockNumber.Uint64() || latest-receipt.BlockNumber.Uint64()+1 < confirmations {
+		return nil, true, nil
+	}
+
+	// the block of the receipt must still be canonical, otherwise it was reorged out
+	header, err := client.HeaderByNumber(callCtx, receipt.BlockNumber)
+	if err != nil || header.Hash() != receipt.BlockHash {
+		return nil, true, ctx.Err()
+	}
+
+	tx, err := t.txByHash(callCtx, txHash)
+	if err != nil {
+		return nil, true, ctx.Err()
+	}
+	result, err = t.parseTxWithReceipt(callCtx, tx, receipt, nil)
+	if err != nil {
+		return nil, true, err
+	}
+	return result, true, nil
+}
+
+func (t *Transaction) txByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+	tx, _, err := t.chain.Client().TransactionByHash(ctx, txHash)
+	return tx, err
+}
+
+// nonceUsed
+//
+//	@Description: whether the nonce has been used by a mined tx
+//	@receiver t
+//	@param ctx
+//	@param from
+//	@param nonce
+//	@return bool
+//	@return error
+func (t *Transaction) nonceUsed(ctx context.Context, from common.Address, nonce uint64) (bool, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+	latestNonce, err := t.chain.Client().NonceAt(ctx, from, nil)
+	if err != nil {
+		return false, err
+	}
+	return latestNonce > nonce, nil
+}
+
+// newHeads
+//
+//	@Description: notify when there may be a new block, subscribe the new heads if the connect supports it, otherwise tick
+//	@receiver t
+//	@param ctx
+//	@return <-chan struct{}
+//	@return func() stop notifying
+func (t *Transaction) newHeads(ctx context.Context) (<-chan struct{}, func()) {
+	notify := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(ctx)
+
+	var sub ethereum.Subscription
+	headers := make(chan *types.Header, 16)
+	client, rpcClient := t.chain.clients()
+	if rpcClient != nil && rpcClient.SupportsSubscriptions() {
+		subCtx, subCancel := t.chain.WithTimeout(ctx)
+		sub, _ = client.SubscribeNewHead(subCtx, headers)
+		subCancel()
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.TxPollInterval) * time.Second)
+		defer ticker.Stop()
+		var subErr <-chan error
+		if sub != nil {
+			defer sub.Unsubscribe()
+			subErr = sub.Err()
+			// the subscription is the main source, ticker is only the fallback
+			ticker.Reset(time.Duration(config.TxPollInterval) * time.Second * 10)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-headers:
+			case <-ticker.C:
+			case <-subErr:
+				subErr = nil
+				ticker.Reset(time.Duration(config.TxPollInterval) * time.Second)
+			}
+			select {
+			case notify <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return notify, cancel
+}
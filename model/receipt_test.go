@@ -0,0 +1,267 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// receiptStub
+//
+//	@Description: the node has never seen the tx, the nonce of the sender is ethStub.nonce
+type receiptStub struct {
+	ethStub
+}
+
+func (r *receiptStub) GetTransactionReceipt(_ common.Hash) (*types.Receipt, error) {
+	return nil, nil
+}
+
+func (r *receiptStub) GetTransactionByHash(_ common.Hash) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func TestWaitForSignedTxReplaced(t *testing.T) {
+	key, err := crypto.HexToECDSA("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	tx, err := types.SignNewTx(key, types.NewLondonSigner(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	require.Nil(t, err)
+
+	// the nonce is not used yet, keep waiting
+	stub := &receiptStub{ethStub: ethStub{nonce: 3}}
+	transaction := NewTransaction(newStubChain(t, stub))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = transaction.WaitForSignedTx(ctx, tx, 1)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// another tx with the same nonce is mined, the tx was never seen
+	stub.setNonce(4)
+	result, err := transaction.WaitForSignedTx(context.Background(), tx, 1)
+	require.Nil(t, err)
+	require.Equal(t, TxStatusReplaced, result.Status)
+	require.Equal(t, uint64(3), result.Nonce)
+	require.Equal(t, testNonceAddress, result.From)
+	require.Equal(t, tx.Hash().Hex(), result.Hash)
+
+	_, err = transaction.WaitForSignedTx(context.Background(), nil, 1)
+	require.NotNil(t, err)
+}
+
+// waitStub
+//
+//	@Description: a chain with new heads by subscription, the tx is pending if it has no receipt and pending is set
+type waitStub struct {
+	*chainStub
+	tx      *types.Transaction
+	pending bool
+	receipt *types.Receipt
+}
+
+func newWaitStub(t *testing.T, count int) *waitStub {
+	key, err := crypto.HexToECDSA("1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f")
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	tx, err := types.SignNewTx(key, types.NewLondonSigner(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	require.Nil(t, err)
+	return &waitStub{chainStub: newChainStub(count), tx: tx}
+}
+
+// mine
+//
+//	@Description: the receipt of the tx in the block of the number on the current chain
+//	@receiver w
+//	@param number
+func (w *waitStub) mine(number int) {
+	hash := common.HexToHash(w.hash(number))
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.receipt = &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      w.tx.Hash(),
+		BlockHash:   hash,
+		BlockNumber: big.NewInt(int64(number)),
+		Logs:        []*types.Log{},
+	}
+}
+
+func (w *waitStub) setPending(pending bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pending = pending
+}
+
+func (w *waitStub) GetTransactionReceipt(_ common.Hash) (*types.Receipt, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.receipt, nil
+}
+
+func (w *waitStub) GetTransactionByHash(_ common.Hash) (map[string]interface{}, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.receipt == nil && !w.pending {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(w.tx)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err = json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	result["blockNumber"] = nil
+	return result, nil
+}
+
+// GetTransactionCount
+//
+//	@Description: the nonce of the tx is never used by another tx
+func (w *waitStub) GetTransactionCount(_ common.Address, _ string) (hexutil.Uint64, error) {
+	return hexutil.Uint64(w.tx.Nonce()), nil
+}
+
+func (w *waitStub) GetBlockByHash(hash common.Hash, _ bool) (map[string]interface{}, error) {
+	w.lock.Lock()
+	number := -1
+	for i, header := range w.headers {
+		if header.Hash() == hash {
+			number = i
+		}
+	}
+	w.lock.Unlock()
+	if number < 0 {
+		return nil, nil
+	}
+	return w.GetBlockByNumber(hexutil.EncodeUint64(uint64(number)), false)
+}
+
+// NewHeads
+//
+//	@Description: notify the head every few milliseconds, so the tests don't wait for config.TxPollInterval
+func (w *waitStub) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		ticker := time.NewTicker(2 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sub.Err():
+				return
+			case <-ticker.C:
+				w.lock.Lock()
+				head := w.headers[len(w.headers)-1]
+				w.lock.Unlock()
+				_ = notifier.Notify(sub.ID, head)
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func TestWaitForReceiptConfirmations(t *testing.T) {
+	// the head is 9, the tx is mined in block 8, it has 2 confirmations
+	stub := newWaitStub(t, 10)
+	stub.mine(8)
+	transaction := NewTransaction(newStubChain(t, stub))
+
+	result, err := transaction.WaitForReceipt(context.Background(), stub.tx.Hash().Hex(), 2)
+	require.Nil(t, err)
+	require.Equal(t, TxStatusSuccess, result.Status)
+	require.Equal(t, uint64(8), result.BlockNumber)
+	require.Equal(t, stub.hash(8), result.BlockHash)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = transaction.WaitForReceipt(ctx, stub.tx.Hash().Hex(), 3)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// a new block, 3 confirmations
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stub.fork(10, 11, 0)
+	}()
+	result, err = transaction.WaitForReceipt(context.Background(), stub.tx.Hash().Hex(), 3)
+	require.Nil(t, err)
+	require.Equal(t, uint64(8), result.BlockNumber)
+}
+
+func TestWaitForReceiptReorg(t *testing.T) {
+	// the block 8 of the receipt is reorged out, the node still returns the old receipt for a while
+	stub := newWaitStub(t, 10)
+	stub.mine(8)
+	stub.fork(8, 10, 1)
+	transaction := NewTransaction(newStubChain(t, stub))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := transaction.WaitForReceipt(ctx, stub.tx.Hash().Hex(), 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the tx is mined again in the new block 9
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stub.mine(9)
+	}()
+	result, err := transaction.WaitForReceipt(context.Background(), stub.tx.Hash().Hex(), 1)
+	require.Nil(t, err)
+	require.Equal(t, uint64(9), result.BlockNumber)
+	require.Equal(t, stub.hash(9), result.BlockHash)
+}
+
+func TestWaitForReceiptDropped(t *testing.T) {
+	stub := newWaitStub(t, 10)
+	stub.setPending(true)
+	transaction := NewTransaction(newStubChain(t, stub))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the pending tx is seen once, then the node drops it
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stub.setPending(false)
+	}()
+	result, err := transaction.WaitForReceipt(ctx, stub.tx.Hash().Hex(), 1)
+	require.Nil(t, err)
+	require.Equal(t, TxStatusDropped, result.Status)
+	require.Equal(t, stub.tx.Hash().Hex(), result.Hash)
+	require.Equal(t, uint64(3), result.Nonce)
+	require.Equal(t, testNonceAddress, result.From)
+
+	// never seen, the sender is unknown
+	result, err = transaction.WaitForReceipt(ctx, stub.tx.Hash().Hex(), 1)
+	require.Nil(t, err)
+	require.Equal(t, TxStatusDropped, result.Status)
+	require.Equal(t, "", result.From)
+}
@@ -0,0 +1,247 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum/rpc"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// chainConnections the connection registry, keyed by the rpc url, or the joined rpc urls of a pool
+var chainConnections = make(map[string]*Chain)
+
+// detachedChains the reconnected chains whose key is taken by a newer connect, they are kept to be closed
+var detachedChains = make(map[*Chain]struct{})
+
+// nonceManagers the nonce managers shared by all connects of a key, the evicted connects and the new ones never hand out the same nonce
+var nonceManagers = make(map[string]*NonceManager)
+
+// dialingChains the keys being dialed, the other callers of the key wait for the result
+var dialingChains = make(map[string]*chainDial)
+var lock sync.RWMutex
+var janitorOnce sync.Once
+
+// chainDial
+//
+//	@Description: the result of one dial, done is closed when it is set
+type chainDial struct {
+	done  chan struct{}
+	chain *Chain
+	err   error
+}
+
+func chainKey(rpcUrls []string) string {
+	return strings.Join(rpcUrls, ",")
+}
+
+// loadChain
+//
+//	@Description: get the connect from the registry, create and store it if it doesn't exist.
+//	the dial is done without lock, so a slow rpc url never blocks the other chains, only one dial of a key runs at a time
+//	@param ctx only stops waiting for the dial of another caller
+//	@param key
+//	@param create
+//	@return *Chain
+//	@return error
+func loadChain(ctx context.Context, key string, create func() (*Chain, error)) (*Chain, error) {
+	lock.Lock()
+	if chain, ok := chainConnections[key]; ok {
+		lock.Unlock()
+		return chain, nil
+	}
+	// 已有其他调用在连接，等待它的结果
+	if call, ok := dialingChains[key]; ok {
+		lock.Unlock()
+		select {
+		case <-call.done:
+			return call.chain, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &chainDial{done: make(chan struct{})}
+	dialingChains[key] = call
+	lock.Unlock()
+
+	// 不持有锁创建
+	chain, err := create()
+
+	var loser *Chain
+	lock.Lock()
+	delete(dialingChains, key)
+	if err == nil {
+		if other, ok := chainConnections[key]; ok {
+			// a reconnected chain of the key has been registered meanwhile, keep it
+			loser, chain = chain, other
+		} else {
+			chain.key = key
+			chainConnections[key] = chain
+			janitorOnce.Do(func() {
+				go janitor()
+			})
+		}
+	}
+	call.chain, call.err = chain, err
+	lock.Unlock()
+	close(call.done)
+
+	if loser != nil {
+		loser.close()
+	}
+	return chain, err
+}
+
+// registerChain
+//
+//	@Description: put the reconnected chain back, so CloseAll and the idle eviction still reach it,
+//	it is detached if GetChain has created another connect of the key meanwhile
+//	@param chain
+func registerChain(chain *Chain) {
+	if chain.key == "" {
+		// not created by the registry
+		return
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if other, ok := chainConnections[chain.key]; !ok || other == chain {
+		chainConnections[chain.key] = chain
+	} else {
+		detachedChains[chain] = struct{}{}
+	}
+	janitorOnce.Do(func() {
+		go janitor()
+	})
+}
+
+// sharedNonceManager
+//
+//	@Description: the nonce manager of the registry key of the chain, it is never removed, so the local nonces survive the reconnects
+//	@param chain
+//	@return *NonceManager
+func sharedNonceManager(chain *Chain) *NonceManager {
+	lock.Lock()
+	defer lock.Unlock()
+	manager, ok := nonceManagers[chain.key]
+	if !ok {
+		manager = NewNonceManager(chain)
+		manager.key = chain.key
+		nonceManagers[chain.key] = manager
+	}
+	return manager
+}
+
+func unregisterChain(chain *Chain) {
+	lock.Lock()
+	defer lock.Unlock()
+	if chainConnections[chain.key] == chain {
+		delete(chainConnections, chain.key)
+	}
+	delete(detachedChains, chain)
+}
+
+// CloseChain
+//
+//	@Description: close the connect of the rpc url and remove it from the cache
+//	@param rpcUrls one rpc url, or all rpc urls of a pool
+func CloseChain(rpcUrls ...string) {
+	key := chainKey(rpcUrls)
+	lock.Lock()
+	chain, ok := chainConnections[key]
+	delete(chainConnections, key)
+	lock.Unlock()
+	if ok {
+		chain.close()
+	}
+}
+
+// CloseAll
+//
+//	@Description: close all cached connects
+func CloseAll() {
+	lock.Lock()
+	chains := chainConnections
+	detached := detachedChains
+	chainConnections = make(map[string]*Chain)
+	detachedChains = make(map[*Chain]struct{})
+	lock.Unlock()
+	for _, chain := range chains {
+		chain.close()
+	}
+	for chain := range detached {
+		chain.close()
+	}
+}
+
+func janitor() {
+	ticker := time.NewTicker(time.Duration(config.ChainCheckInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		checkChains(context.Background(), time.Duration(config.ChainIdleTimeout)*time.Second)
+	}
+}
+
+// checkChains
+//
+//	@Description: close the idle connects, reconnect the broken ones, the pools check themselves
+//	@param ctx
+//	@param idleTimeout
+func checkChains(ctx context.Context, idleTimeout time.Duration) {
+	var idle, alive []*Chain
+	deadline := time.Now().Add(-idleTimeout).UnixNano()
+
+	lock.Lock()
+	for key, chain := range chainConnections {
+		if atomic.LoadInt64(&chain.lastUsed) < deadline {
+			delete(chainConnections, key)
+			idle = append(idle, chain)
+		} else {
+			alive = append(alive, chain)
+		}
+	}
+	for chain := range detachedChains {
+		if atomic.LoadInt64(&chain.lastUsed) < deadline {
+			delete(detachedChains, chain)
+			idle = append(idle, chain)
+		}
+	}
+	lock.Unlock()
+
+	for _, chain := range idle {
+		chain.close()
+	}
+	for _, chain := range alive {
+		chain.connLock.RLock()
+		client, closed, pooled := chain.client, chain.closed, chain.pool != nil
+		chain.connLock.RUnlock()
+		if closed || pooled {
+			continue
+		}
+		callCtx, cancel := chain.WithTimeout(ctx)
+		_, err := client.BlockNumber(callCtx)
+		cancel()
+		if isTransportError(err) {
+			_ = chain.Reconnect(ctx)
+		}
+	}
+}
+
+// isTransportError
+//
+//	@Description: the connect is broken, not an error answered by the node
+//	@param err
+//	@return bool
+func isTransportError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	return !errors.As(err, &httpErr)
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newStubUrl(t *testing.T, service interface{}) string {
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	require.Nil(t, server.RegisterName("eth", service))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestChainRegistry(t *testing.T) {
+	stub := &ethStub{blockNumber: 100}
+	url := newStubUrl(t, stub)
+	defer CloseAll()
+
+	var wg sync.WaitGroup
+	chains := make([]*Chain, 10)
+	for i := range chains {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			chain, err := GetChain(url, 10)
+			require.Nil(t, err)
+			chains[i] = chain
+		}(i)
+	}
+	wg.Wait()
+	for _, chain := range chains {
+		require.Same(t, chains[0], chain)
+	}
+
+	// the closed chain is removed from the cache
+	CloseChain(url)
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	require.NotSame(t, chains[0], chain)
+
+	// the closed chain connects again when it is used
+	number, err := chains[0].Client().BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+
+	// the remote client of the http url goes through the reconnected client
+	remote := chain.RemoteRpcClient
+	require.Nil(t, chain.Reconnect(context.Background()))
+	require.Same(t, remote, chain.RemoteRpcClient)
+	number, err = remote.BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+
+	// it is still closed by CloseAll though the key is taken by the new one
+	CloseAll()
+	require.False(t, chains[0].Endpoints()[0].Healthy)
+	require.False(t, chain.Endpoints()[0].Healthy)
+}
+
+func TestChainIdleEviction(t *testing.T) {
+	stub := &ethStub{blockNumber: 100}
+	url := newStubUrl(t, stub)
+	defer CloseAll()
+
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	atomic.StoreInt64(&chain.lastUsed, time.Now().Add(-time.Hour).UnixNano())
+	checkChains(context.Background(), time.Minute)
+
+	lock.RLock()
+	_, ok := chainConnections[url]
+	lock.RUnlock()
+	require.False(t, ok)
+	require.False(t, chain.Endpoints()[0].Healthy)
+
+	number, err := chain.Client().BlockNumber(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), number)
+
+	// it goes back to the cache
+	cached, err := GetChain(url, 10)
+	require.Nil(t, err)
+	require.Same(t, chain, cached)
+	chain.Close()
+}
+
+// dialStub
+//
+//	@Description: count the dials by the chain id calls
+type dialStub struct {
+	*ethStub
+	dials atomic.Int32
+}
+
+func (d *dialStub) ChainId() (hexutil.Big, error) {
+	d.dials.Add(1)
+	return d.ethStub.ChainId()
+}
+
+func TestChainReconnectConcurrently(t *testing.T) {
+	stub := &dialStub{ethStub: &ethStub{blockNumber: 100}}
+	url := newStubUrl(t, stub)
+	defer CloseAll()
+
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	chain.Close()
+
+	// the closed chain is connected again once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := chain.Client().BlockNumber(context.Background())
+			require.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), stub.dials.Load())
+}
+
+func TestChainReconnect(t *testing.T) {
+	stub := &ethStub{blockNumber: 100}
+	url := newStubUrl(t, stub)
+	defer CloseAll()
+
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	require.Nil(t, chain.Reconnect(context.Background()))
+
+	// the node of the url is another chain now
+	stub.lock.Lock()
+	stub.chainId = 2
+	stub.lock.Unlock()
+	require.NotNil(t, chain.Reconnect(context.Background()))
+}
+
+func TestChainRegistryNonceManager(t *testing.T) {
+	stub := &ethStub{nonce: 8}
+	url := newStubUrl(t, stub)
+	defer CloseAll()
+
+	old, err := GetChain(url, 10)
+	require.Nil(t, err)
+	nonce, err := old.NonceManager().Next(context.Background(), testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(8), nonce)
+
+	// the evicted chain is still used by a long-lived scanner, the new one never repeats its nonces
+	CloseChain(url)
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	require.NotSame(t, old, chain)
+	require.Same(t, old.NonceManager(), chain.NonceManager())
+	nonce, err = chain.NonceManager().Next(context.Background(), testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(9), nonce)
+}
+
+// slowDialStub
+//
+//	@Description: the chain id call is blocked until release is closed
+type slowDialStub struct {
+	*ethStub
+	dials   atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *slowDialStub) ChainId() (hexutil.Big, error) {
+	if s.dials.Add(1) == 1 {
+		close(s.entered)
+	}
+	<-s.release
+	return s.ethStub.ChainId()
+}
+
+func TestChainRegistrySlowDial(t *testing.T) {
+	slow := &slowDialStub{ethStub: &ethStub{blockNumber: 100}, entered: make(chan struct{}), release: make(chan struct{})}
+	slowUrl := newStubUrl(t, slow)
+	url := newStubUrl(t, &ethStub{nonce: 3})
+	defer CloseAll()
+
+	var wg sync.WaitGroup
+	chains := make([]*Chain, 3)
+	for i := range chains {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			chain, err := GetChain(slowUrl, 10)
+			require.Nil(t, err)
+			chains[i] = chain
+		}(i)
+	}
+	<-slow.entered
+
+	// the other chains are not blocked by the dial
+	chain, err := GetChain(url, 10)
+	require.Nil(t, err)
+	nonce, err := chain.NonceManager().Next(context.Background(), testNonceAddress)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+	CloseChain(url)
+
+	// the waiter stops by its ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = GetChainCtx(ctx, slowUrl, 10)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// all callers get the chain of one dial
+	close(slow.release)
+	wg.Wait()
+	require.Equal(t, int32(1), slow.dials.Load())
+	for _, c := range chains {
+		require.Same(t, chains[0], c)
+	}
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/common"
+	eTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+)
+
+func (t *Token) SpeedUp(hash string, s signer.Signer, bumpPercent int64) (string, error) {
+	return t.SpeedUpCtx(context.Background(), hash, s, bumpPercent)
+}
+
+// SpeedUpCtx
+//
+//	@Description: replace a pending tx by the same tx with higher fees
+//	@receiver t
+//	@param ctx
+//	@param hash the pending tx
+//	@param s the signer of the pending tx
+//	@param bumpPercent the fees raised, at least config.TxReplaceMinBumpPercent
+//	@return string the hash of the new tx
+//	@return error
+func (t *Token) SpeedUpCtx(ctx context.Context, hash string, s signer.Signer, bumpPercent int64) (string, error) {
+	tx, err := t.pendingTx(ctx, hash, s)
+	if err != nil {
+		return "", err
+	}
+	return t.replaceTx(ctx, s, tx, tx.To(), tx.Value(), tx.Gas(), tx.Data(), bumpPercent)
+}
+
+func (t *Token) Cancel(hash string, s signer.Signer) (string, error) {
+	return t.CancelCtx(context.Background(), hash, s)
+}
+
+// CancelCtx
+//
+//	@Description: replace a pending tx by a 0 value transfer to the sender self with the same nonce
+//	@receiver t
+//	@param ctx
+//	@param hash the pending tx
+//	@param s the signer of the pending tx
+//	@return string the hash of the new tx
+//	@return error
+func (t *Token) CancelCtx(ctx context.Context, hash string, s signer.Signer) (string, error) {
+	tx, err := t.pendingTx(ctx, hash, s)
+	if err != nil {
+		return "", err
+	}
+	to := s.Address()
+	return t.replaceTx(ctx, s, tx, &to, new(big.Int), params.TxGas, nil, config.TxReplaceMinBumpPercent)
+}
+
+// pendingTx
+//
+//	@Description: only a pending tx sent by the signer can be replaced
+//	@receiver t
+//	@param ctx
+//	@param hash
+//	@param s
+//	@return *eTypes.Transaction
+//	@return error
+func (t *Token) pendingTx(ctx context.Context, hash string, s signer.Signer) (*eTypes.Transaction, error) {
+	if t.chain == nil {
+		return nil, errors.New("the chain node is empty")
+	}
+	if s == nil || hash == "" {
+		return nil, errors.New("param is error")
+	}
+
+	callCtx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+	tx, isPending, err := t.chain.Client().TransactionByHash(callCtx, common.HexToHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	if !isPending {
+		return nil, errors.New("the tx is not pending")
+	}
+	from, err := txSender(tx)
+	if err != nil {
+		return nil, err
+	}
+	if from != s.Address() {
+		return nil, errors.New("the tx is not sent by the signer")
+	}
+	return tx, nil
+}
+
+// replaceTx
+//
+//	@Description: sign and send a new tx with the nonce of the old one, both the fee cap and the tip are raised by bumpPercent,
+//	and never lower than the standard fees of the fee oracle
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param old
+//	@param to
+//	@param value
+//	@param gas
+//	@param data
+//	@param bumpPercent
+//	@return string
+//	@return error
+func (t *Token) replaceTx(ctx context.Context, s signer.Signer, old *eTypes.Transaction, to *common.Address, value *big.Int, gas uint64, data []byte, bumpPercent int64) (string, error) {
+	switch old.Type() {
+	case eTypes.LegacyTxType, eTypes.AccessListTxType, eTypes.DynamicFeeTxType:
+	default:
+		// eg: the blob txs need the blob sidecar and a bumped blob fee
+		return "", fmt.Errorf("the tx of type %d can't be replaced", old.Type())
+	}
+	if bumpPercent < config.TxReplaceMinBumpPercent {
+		bumpPercent = config.TxReplaceMinBumpPercent
+	}
+	fees, err := t.chain.EstimateFeesCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	standardFee, _ := new(big.Int).SetString(fees.Standard.MaxFeePerGas, 10)
+
+	var tx *eTypes.Transaction
+	switch old.Type() {
+	case eTypes.DynamicFeeTxType:
+		tip := bumpFee(old.GasTipCap(), bumpPercent)
+		if !fees.IsLegacy {
+			standardTip, _ := new(big.Int).SetString(fees.Standard.MaxPriorityFeePerGas, 10)
+			tip = maxBig(tip, standardTip)
+		}
+		feeCap := maxBig(bumpFee(old.GasFeeCap(), bumpPercent), standardFee, tip)
+		tx = eTypes.NewTx(&eTypes.DynamicFeeTx{
+			ChainID:    t.chain.ChainId,
+			Nonce:      old.Nonce(),
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: old.AccessList(),
+		})
+	case eTypes.AccessListTxType:
+		tx = eTypes.NewTx(&eTypes.AccessListTx{
+			ChainID:    t.chain.ChainId,
+			Nonce:      old.Nonce(),
+			GasPrice:   maxBig(bumpFee(old.GasPrice(), bumpPercent), standardFee),
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: old.AccessList(),
+		})
+	case eTypes.LegacyTxType:
+		tx = eTypes.NewTx(&eTypes.LegacyTx{
+			Nonce:    old.Nonce(),
+			GasPrice: maxBig(bumpFee(old.GasPrice(), bumpPercent), standardFee),
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		})
+	}
+
+	txSign, err := t.chain.BuildTxSignWithSigner(ctx, s, tx)
+	if err != nil {
+		return "", err
+	}
+	return txSign.TxHex, t.chain.SendTxCtx(ctx, txSign.SignedTx)
+}
+
+// bumpFee
+//
+//	@Description: fee * (100 + percent) / 100, rounded up, so the node's replacement check always passes
+//	@param fee
+//	@param percent
+//	@return *big.Int
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(values ...*big.Int) *big.Int {
+	result := new(big.Int)
+	for _, value := range values {
+		if value != nil && value.Cmp(result) > 0 {
+			result.Set(value)
+		}
+	}
+	return result
+}
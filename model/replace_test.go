@@ -0,0 +1,149 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+const testReplaceKey = "1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f"
+
+// replaceStub
+//
+//	@Description: a legacy chain with a pending tx, the replacement is kept
+type replaceStub struct {
+	legacyFeeStub
+	pending *types.Transaction
+	sent    *types.Transaction
+}
+
+func (r *replaceStub) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	if r.pending == nil || r.pending.Hash() != hash {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(r.pending)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err = json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	result["blockNumber"] = nil
+	return result, nil
+}
+
+func (r *replaceStub) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	r.sent = tx
+	return tx.Hash(), nil
+}
+
+// dynamicReplaceStub
+//
+//	@Description: an eip1559 chain, the next base fee is 100 and the standard tip is 2,
+//	so the standard max fee is 100 * 1.125^3 + 2 = 143
+type dynamicReplaceStub struct {
+	replaceStub
+}
+
+func (d *dynamicReplaceStub) FeeHistory(_ hexutil.Uint64, _ string, _ []float64) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"oldestBlock":   "0x1",
+		"baseFeePerGas": []string{"0x64", "0x64"},
+		"gasUsedRatio":  []float64{0.5},
+		"reward":        [][]string{{"0x1", "0x2", "0x3"}},
+	}, nil
+}
+
+func newReplaceTx(t *testing.T, s signer.Signer, data types.TxData) *types.Transaction {
+	tx, err := s.SignTx(context.Background(), types.NewTx(data), big.NewInt(1))
+	require.Nil(t, err)
+	return tx
+}
+
+func TestBumpFee(t *testing.T) {
+	// 16.5 is rounded up, so the node's 10% check passes
+	require.Equal(t, big.NewInt(17), bumpFee(big.NewInt(15), 10))
+	require.Equal(t, big.NewInt(110), bumpFee(big.NewInt(100), 10))
+	require.Zero(t, bumpFee(big.NewInt(0), 10).Sign())
+}
+
+func TestSpeedUpDynamicFee(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testReplaceKey)
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	stub := &dynamicReplaceStub{}
+	token := NewToken(newStubChain(t, stub))
+
+	// the bumped fees are above the oracle
+	stub.pending = newReplaceTx(t, s, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 4, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(1000), Gas: 30000, To: &to, Value: big.NewInt(5)})
+	hash, err := token.SpeedUpCtx(context.Background(), stub.pending.Hash().Hex(), s, 5)
+	require.Nil(t, err)
+	require.Equal(t, stub.sent.Hash().Hex(), hash)
+	require.Equal(t, uint8(types.DynamicFeeTxType), stub.sent.Type())
+	require.Equal(t, uint64(4), stub.sent.Nonce())
+	require.Equal(t, big.NewInt(11), stub.sent.GasTipCap())
+	require.Equal(t, big.NewInt(1100), stub.sent.GasFeeCap())
+	require.Equal(t, uint64(30000), stub.sent.Gas())
+	require.Equal(t, big.NewInt(5), stub.sent.Value())
+
+	// the oracle is the floor
+	stub.pending = newReplaceTx(t, s, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 5, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(50), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	_, err = token.SpeedUpCtx(context.Background(), stub.pending.Hash().Hex(), s, 10)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(2), stub.sent.GasTipCap())
+	require.Equal(t, big.NewInt(143), stub.sent.GasFeeCap())
+}
+
+func TestCancelLegacy(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testReplaceKey)
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	stub := &replaceStub{}
+	token := NewToken(newStubChain(t, stub))
+
+	// the gas price of the node is 1000
+	stub.pending = newReplaceTx(t, s, &types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(2000), Gas: 60000, To: &to, Value: big.NewInt(5), Data: []byte{1}})
+	_, err = token.CancelCtx(context.Background(), stub.pending.Hash().Hex(), s)
+	require.Nil(t, err)
+	require.Equal(t, uint8(types.LegacyTxType), stub.sent.Type())
+	require.Equal(t, uint64(7), stub.sent.Nonce())
+	require.Equal(t, big.NewInt(2200), stub.sent.GasPrice())
+	require.Equal(t, params.TxGas, stub.sent.Gas())
+	require.Equal(t, s.Address(), *stub.sent.To())
+	require.Zero(t, stub.sent.Value().Sign())
+	require.Len(t, stub.sent.Data(), 0)
+
+	stub.pending = newReplaceTx(t, s, &types.AccessListTx{ChainID: big.NewInt(1), Nonce: 8, GasPrice: big.NewInt(500), Gas: 21000, To: &to})
+	_, err = token.SpeedUpCtx(context.Background(), stub.pending.Hash().Hex(), s, 10)
+	require.Nil(t, err)
+	require.Equal(t, uint8(types.AccessListTxType), stub.sent.Type())
+	require.Equal(t, big.NewInt(1000), stub.sent.GasPrice())
+
+	// not sent by the signer
+	other, err := signer.NewPrivateKeySigner("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	require.Nil(t, err)
+	_, err = token.CancelCtx(context.Background(), stub.pending.Hash().Hex(), other)
+	require.NotNil(t, err)
+}
+
+func TestReplaceBlobTx(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testReplaceKey)
+	require.Nil(t, err)
+	stub := &replaceStub{}
+	token := NewToken(newStubChain(t, stub))
+	_, err = token.replaceTx(context.Background(), s, types.NewTx(&types.BlobTx{Nonce: 1}), nil, new(big.Int), params.TxGas, nil, 10)
+	require.NotNil(t, err)
+	require.Nil(t, stub.sent)
+}
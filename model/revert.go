@@ -0,0 +1,234 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"strings"
+)
+
+var (
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector  = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons the readable panic codes of solidity
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertError
+//
+//	@Description: the reason of a reverted call or tx, decoded from the revert data
+type RevertError struct {
+	Data      []byte        // raw revert data
+	Reason    string        // Error(string), or the description of the panic code
+	PanicCode *big.Int      // Panic(uint256), nil if it is not a panic
+	ErrorName string        // name of the custom error, found in the abi
+	Args      []interface{} // arguments of the custom error
+	Err       error         // the original error of the node, may be nil
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.PanicCode != nil:
+		return fmt.Sprintf("execution reverted: panic 0x%x (%s)", e.PanicCode, e.Reason)
+	case e.ErrorName != "":
+		return fmt.Sprintf("execution reverted: %s%v", e.ErrorName, e.Args)
+	case e.Reason != "":
+		return "execution reverted: " + e.Reason
+	case len(e.Data) > 0:
+		return "execution reverted: " + hexutil.Encode(e.Data)
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return "execution reverted"
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeRevert
+//
+//	@Description: decode the revert data, Error(string), Panic(uint256) or a custom error in contractAbi
+//	@param data
+//	@param contractAbi can be nil if there is no custom error
+//	@return *RevertError
+func DecodeRevert(data []byte, contractAbi *abi.ABI) *RevertError {
+	revertErr := &RevertError{Data: data}
+	if len(data) < 4 {
+		return revertErr
+	}
+
+	selector, payload := data[:4], data[4:]
+	switch {
+	case bytes.Equal(selector, revertSelector):
+		typ, _ := abi.NewType("string", "", nil)
+		values, err := (abi.Arguments{{Type: typ}}).Unpack(payload)
+		if err == nil && len(values) == 1 {
+			revertErr.Reason, _ = values[0].(string)
+		}
+	case bytes.Equal(selector, panicSelector):
+		typ, _ := abi.NewType("uint256", "", nil)
+		values, err := (abi.Arguments{{Type: typ}}).Unpack(payload)
+		if err == nil && len(values) == 1 {
+			revertErr.PanicCode, _ = values[0].(*big.Int)
+		}
+		if revertErr.PanicCode != nil {
+			revertErr.Reason = "unknown panic code"
+			if revertErr.PanicCode.IsUint64() {
+				if reason, ok := panicReasons[revertErr.PanicCode.Uint64()]; ok {
+					revertErr.Reason = reason
+				}
+			}
+		}
+	case contractAbi != nil:
+		var id [4]byte
+		copy(id[:], selector)
+		abiErr, err := contractAbi.ErrorByID(id)
+		if err != nil {
+			return revertErr
+		}
+		values, err := abiErr.Inputs.Unpack(payload)
+		if err != nil {
+			return revertErr
+		}
+		revertErr.ErrorName = abiErr.Name
+		revertErr.Args = values
+	}
+	return revertErr
+}
+
+// RevertData
+//
+//	@Description: get the revert data from the json rpc error of eth_call, eth_estimateGas or eth_sendRawTransaction
+//	@param err
+//	@return []byte
+//	@return bool
+func RevertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		b, decodeErr := hexutil.Decode(data)
+		if decodeErr != nil {
+			return nil, false
+		}
+		return b, true
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
+
+// WrapRevertError
+//
+//	@Description: turn the error of the node into *RevertError if it is a revert, otherwise keep it
+//	@param err
+//	@param contractAbi can be nil
+//	@return error
+func WrapRevertError(err error, contractAbi *abi.ABI) error {
+	if err == nil {
+		return nil
+	}
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return err
+	}
+	data, ok := RevertData(err)
+	if !ok {
+		if !strings.Contains(err.Error(), "execution reverted") {
+			return err
+		}
+		// some nodes only return the message
+		return &RevertError{Err: err}
+	}
+	revertErr = DecodeRevert(data, contractAbi)
+	revertErr.Err = err
+	return revertErr
+}
+
+func (t *Transaction) ReplayFailedTx(hash string, contractAbi *abi.ABI) (*RevertError, error) {
+	return t.ReplayFailedTxCtx(context.Background(), hash, contractAbi)
+}
+
+// ReplayFailedTxCtx
+//
+//	@Description: run the failed tx again by eth_call on the state before its block to recover the revert reason.
+//	the fees are not sent, so the balance check can't fail instead of the revert. the txs before it in the same block
+//	are not applied, so the reason may differ if they changed the state it depends on
+//	@receiver t
+//	@param ctx
+//	@param hash
+//	@param contractAbi used to decode the custom errors, can be nil
+//	@return *RevertError
+//	@return error the tx is not failed, or the replay doesn't revert
+func (t *Transaction) ReplayFailedTxCtx(ctx context.Context, hash string, contractAbi *abi.ABI) (*RevertError, error) {
+	if t.chain == nil {
+		return nil, errors.New("the chain node is empty")
+	}
+	ctx, cancel := t.chain.WithTimeout(ctx)
+	defer cancel()
+
+	txHash := common.HexToHash(hash)
+	client := t.chain.Client()
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, errors.New("the tx is not failed")
+	}
+	tx, _, err := client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	from, err := txSender(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the state before the block, the sender has not paid for this tx and the later ones yet
+	block := new(big.Int).Set(receipt.BlockNumber)
+	if block.Sign() > 0 {
+		block.Sub(block, common.Big1)
+	}
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	_, err = client.CallContract(ctx, msg, block)
+	if err == nil {
+		return nil, errors.New("the replay of the tx doesn't revert")
+	}
+	var revertErr *RevertError
+	if errors.As(WrapRevertError(err, contractAbi), &revertErr) {
+		return revertErr, nil
+	}
+	return nil, err
+}
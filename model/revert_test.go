@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/model/signer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const testErrorAbi = `[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+
+// rpcDataError
+//
+//	@Description: the same as the json rpc error with data returned by the node
+type rpcDataError struct {
+	data interface{}
+}
+
+func (e *rpcDataError) Error() string {
+	return "execution reverted"
+}
+
+func (e *rpcDataError) ErrorData() interface{} {
+	return e.data
+}
+
+func TestDecodeRevert(t *testing.T) {
+	// Error(string) "not enough"
+	data := hexutil.MustDecode("0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000a6e6f7420656e6f75676800000000000000000000000000000000000000000000")
+	revertErr := DecodeRevert(data, nil)
+	require.Equal(t, "not enough", revertErr.Reason)
+	require.Nil(t, revertErr.PanicCode)
+
+	// Panic(0x11)
+	data = hexutil.MustDecode("0x4e487b710000000000000000000000000000000000000000000000000000000000000011")
+	revertErr = DecodeRevert(data, nil)
+	require.Equal(t, big.NewInt(0x11), revertErr.PanicCode)
+	require.Equal(t, "arithmetic underflow or overflow", revertErr.Reason)
+
+	// custom error
+	contractAbi, err := abi.JSON(strings.NewReader(testErrorAbi))
+	require.Nil(t, err)
+	abiErr := contractAbi.Errors["InsufficientBalance"]
+	data, err = abiErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	require.Nil(t, err)
+	data = append(common.CopyBytes(abiErr.ID[:4]), data...)
+	revertErr = DecodeRevert(data, &contractAbi)
+	require.Equal(t, "InsufficientBalance", revertErr.ErrorName)
+	require.Equal(t, []interface{}{big.NewInt(1), big.NewInt(2)}, revertErr.Args)
+
+	// unknown custom error without abi
+	revertErr = DecodeRevert(data, nil)
+	require.Equal(t, "", revertErr.ErrorName)
+	require.Equal(t, data, revertErr.Data)
+}
+
+func TestWrapRevertError(t *testing.T) {
+	nodeErr := &rpcDataError{data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000001"}
+	err := WrapRevertError(nodeErr, nil)
+	var revertErr *RevertError
+	require.True(t, errors.As(err, &revertErr))
+	require.Equal(t, "assert(false)", revertErr.Reason)
+	require.ErrorIs(t, err, nodeErr)
+
+	plainErr := errors.New("nonce too low")
+	require.Equal(t, plainErr, WrapRevertError(plainErr, nil))
+}
+
+// replayStub
+//
+//	@Description: the pending tx failed in block 10, eth_call reverts with assert(false)
+type replayStub struct {
+	replaceStub
+	block string
+	args  map[string]interface{}
+}
+
+func (r *replayStub) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{
+		Status:      types.ReceiptStatusFailed,
+		TxHash:      hash,
+		BlockNumber: big.NewInt(10),
+		Logs:        []*types.Log{},
+	}, nil
+}
+
+func (r *replayStub) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	r.args, r.block = args, block
+	return nil, &rpcDataError{data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000001"}
+}
+
+func TestReplayFailedTx(t *testing.T) {
+	s, err := signer.NewPrivateKeySigner(testReplaceKey)
+	require.Nil(t, err)
+	to := common.HexToAddress(testNonceAddress)
+	stub := &replayStub{}
+	stub.pending = newReplaceTx(t, s, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(1000), Gas: 30000, To: &to, Value: big.NewInt(5), Data: []byte{1}})
+
+	revertErr, err := NewTransaction(newStubChain(t, stub)).ReplayFailedTxCtx(context.Background(), stub.pending.Hash().Hex(), nil)
+	require.Nil(t, err)
+	require.Equal(t, "assert(false)", revertErr.Reason)
+
+	// the state before the block, without the fees
+	require.Equal(t, "0x9", stub.block)
+	require.Equal(t, s.Address().Hex(), common.HexToAddress(stub.args["from"].(string)).Hex())
+	require.NotContains(t, stub.args, "gasPrice")
+	require.NotContains(t, stub.args, "maxFeePerGas")
+	require.NotContains(t, stub.args, "maxPriorityFeePerGas")
+}
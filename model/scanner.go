@@ -0,0 +1,343 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sync"
+)
+
+// ScannerOptions
+//
+//	@Description: the handlers are called in the order of the chain, a nil handler is skipped,
+//	the receipts and the logs are only fetched if their handler is set
+type ScannerOptions struct {
+	StartBlock    uint64 // the first block if no checkpoint is saved
+	Confirmations uint64 // only the blocks with enough confirmations are scanned, the head has 1, 0 is the same as 1, like WaitForReceipt
+	ReorgDepth    uint64 // the recent blocks kept to find the common ancestor, 0 is config.ScanReorgDepth
+	Store         CheckpointStore
+
+	LogAddresses []string   // the filter of OnLog, empty is any
+	LogTopics    [][]string // the filter of OnLog, like LogQuery.Topics
+	Abis         []*abi.ABI // decode the logs of OnLog
+
+	// TransactionFilter only the txs passing it are parsed with their receipts for OnTransaction, nil is all
+	TransactionFilter func(tx *types.Transaction) bool
+
+	OnBlock       func(ctx context.Context, block *types.Block) error
+	OnTransaction func(ctx context.Context, tx *Transaction) error
+	OnLog         func(ctx context.Context, log EventLog) error
+	// OnRollback the blocks after number are reorged out, undo what the handlers did for them
+	OnRollback func(ctx context.Context, number uint64) error
+}
+
+// ErrReorgTooDeep no recent block is canonical, the checkpoint must be fixed by hand
+var ErrReorgTooDeep = errors.New("the reorg is deeper than the recent blocks")
+
+// ScanHandlerError
+//
+//	@Description: a handler failed, the block is scanned again next time. the errors of the node are never wrapped by it
+type ScanHandlerError struct {
+	Number uint64
+	Err    error
+}
+
+func (e *ScanHandlerError) Error() string {
+	return fmt.Sprintf("scan block %d: %s", e.Number, e.Err)
+}
+
+func (e *ScanHandlerError) Unwrap() error {
+	return e.Err
+}
+
+// Scanner
+//
+//	@Description: walk the blocks from a start height, follow the head and roll back on reorgs
+type Scanner struct {
+	chain      *Chain
+	options    ScannerOptions
+	lock       sync.Mutex
+	checkpoint *Checkpoint
+	loaded     bool
+	head       uint64 // the head of the node when the scan started
+}
+
+func NewScanner(chain *Chain, options ScannerOptions) *Scanner {
+	if options.ReorgDepth == 0 {
+		options.ReorgDepth = config.ScanReorgDepth
+	}
+	if options.Store == nil {
+		options.Store = NewMemoryCheckpointStore()
+	}
+	return &Scanner{
+		chain:   chain,
+		options: options,
+	}
+}
+
+// Run
+//
+//	@Description: scan until ctx is done, the new blocks are found by websocket if supported, otherwise by polling.
+//	the errors of the node are retried on the next head
+//	@receiver s
+//	@param ctx
+//	@return error ctx.Err(), *ScanHandlerError or ErrReorgTooDeep
+func (s *Scanner) Run(ctx context.Context) error {
+	heads, stop := NewTransaction(s.chain).newHeads(ctx)
+	defer stop()
+	for {
+		err := s.Scan(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var handlerErr *ScanHandlerError
+		if errors.As(err, &handlerErr) || errors.Is(err, ErrReorgTooDeep) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-heads:
+		}
+	}
+}
+
+// Scan
+//
+//	@Description: scan the blocks from the checkpoint to the head minus the confirmations once
+//	@receiver s
+//	@param ctx
+//	@return error
+func (s *Scanner) Scan(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.chain == nil {
+		return errors.New("the chain node is empty")
+	}
+	if !s.loaded {
+		checkpoint, err := s.options.Store.Load(ctx)
+		if err != nil {
+			return err
+		}
+		s.checkpoint = checkpoint
+		s.loaded = true
+	}
+
+	callCtx, cancel := s.chain.WithTimeout(ctx)
+	head, err := s.chain.Client().BlockNumber(callCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	s.head = head
+	confirmations := s.options.Confirmations
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	if head+1 < confirmations {
+		return nil
+	}
+	target := head + 1 - confirmations
+
+	for {
+		next := s.options.StartBlock
+		if s.checkpoint != nil {
+			next = s.checkpoint.Number + 1
+		}
+		if next > target {
+			return nil
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		block, err := s.blockByNumber(ctx, next)
+		if err != nil {
+			return err
+		}
+		if s.checkpoint != nil && block.ParentHash() != common.HexToHash(s.checkpoint.Hash) {
+			if err = s.rollback(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = s.handle(ctx, block); err != nil {
+			return err
+		}
+		if err = s.save(ctx, ScannedBlock{Number: next, Hash: block.Hash().Hex()}); err != nil {
+			return err
+		}
+	}
+}
+
+// Checkpoint
+//
+//	@Description: the last handled block, nil if nothing is scanned
+//	@receiver s
+//	@return *Checkpoint
+func (s *Scanner) Checkpoint() *Checkpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.checkpoint.clone()
+}
+
+// handle
+//
+//	@Description: call the handlers of the block, the txs and the logs
+//	@receiver s
+//	@param ctx
+//	@param block
+//	@return error *ScanHandlerError if a handler failed, the errors of the node as they are
+func (s *Scanner) handle(ctx context.Context, block *types.Block) error {
+	if s.options.OnBlock != nil {
+		if err := s.options.OnBlock(ctx, block); err != nil {
+			return &ScanHandlerError{Number: block.NumberU64(), Err: err}
+		}
+	}
+
+	if s.options.OnTransaction != nil {
+		txs, receipts, err := s.receipts(ctx, block)
+		if err != nil {
+			return err
+		}
+		transaction := NewTransaction(s.chain)
+		header := block.Header()
+		for i, tx := range txs {
+			result, err := transaction.parseTxWithReceipt(ctx, tx, receipts[i], header)
+			if err != nil {
+				return err
+			}
+			if err = s.options.OnTransaction(ctx, result); err != nil {
+				return &ScanHandlerError{Number: block.NumberU64(), Err: err}
+			}
+		}
+	}
+
+	if s.options.OnLog != nil {
+		filter, err := LogQuery{Addresses: s.options.LogAddresses, Topics: s.options.LogTopics}.filterQuery()
+		if err != nil {
+			return err
+		}
+		// by the block hash, the logs of a reorged block are never mixed in
+		hash := block.Hash()
+		filter.BlockHash = &hash
+		callCtx, cancel := s.chain.WithTimeout(ctx)
+		logs, err := s.chain.Client().FilterLogs(callCtx, filter)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, log := range logs {
+			if err = s.options.OnLog(ctx, decodeEventLog(log, s.options.Abis)); err != nil {
+				return &ScanHandlerError{Number: block.NumberU64(), Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// receipts
+//
+//	@Description: the txs passing TransactionFilter and their receipts, all receipts of the block are fetched at once without a filter
+//	@receiver s
+//	@param ctx
+//	@param block
+//	@return []*types.Transaction
+//	@return []*types.Receipt
+//	@return error
+func (s *Scanner) receipts(ctx context.Context, block *types.Block) ([]*types.Transaction, []*types.Receipt, error) {
+	if s.options.TransactionFilter == nil {
+		receipts, err := s.chain.blockReceipts(ctx, block)
+		return block.Transactions(), receipts, err
+	}
+
+	txs := make([]*types.Transaction, 0, len(block.Transactions()))
+	hashes := make([]string, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		if s.options.TransactionFilter(tx) {
+			txs = append(txs, tx)
+			hashes = append(hashes, tx.Hash().Hex())
+		}
+	}
+	results, err := s.chain.ReceiptsOfCtx(ctx, hashes, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipts := make([]*types.Receipt, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, nil, result.Err
+		}
+		receipts[i] = result.Receipt
+	}
+	// by the tx hash, the receipts may be of another block if it is reorged meanwhile
+	if err = checkReceipts(block, txs, receipts); err != nil {
+		return nil, nil, err
+	}
+	return txs, receipts, nil
+}
+
+// rollback
+//
+//	@Description: find the newest recent block which is still canonical, undo the blocks after it
+//	@receiver s
+//	@param ctx
+//	@return error the reorg is deeper than the recent blocks
+func (s *Scanner) rollback(ctx context.Context) error {
+	recent := s.checkpoint.Recent
+	for i := len(recent) - 1; i >= 0; i-- {
+		callCtx, cancel := s.chain.WithTimeout(ctx)
+		header, err := s.chain.Client().HeaderByNumber(callCtx, new(big.Int).SetUint64(recent[i].Number))
+		cancel()
+		if err != nil {
+			return err
+		}
+		if header.Hash() != common.HexToHash(recent[i].Hash) {
+			continue
+		}
+
+		if s.options.OnRollback != nil {
+			if err = s.options.OnRollback(ctx, recent[i].Number); err != nil {
+				return &ScanHandlerError{Number: recent[i].Number, Err: err}
+			}
+		}
+		checkpoint := &Checkpoint{
+			Number: recent[i].Number,
+			Hash:   recent[i].Hash,
+			Recent: append([]ScannedBlock(nil), recent[:i+1]...),
+		}
+		if err = s.options.Store.Save(ctx, checkpoint); err != nil {
+			return err
+		}
+		s.checkpoint = checkpoint
+		return nil
+	}
+	return fmt.Errorf("%w, block %d, %d recent blocks", ErrReorgTooDeep, s.checkpoint.Number, len(recent))
+}
+
+func (s *Scanner) save(ctx context.Context, block ScannedBlock) error {
+	checkpoint := &Checkpoint{Number: block.Number, Hash: block.Hash}
+	if s.checkpoint != nil {
+		checkpoint.Recent = append(checkpoint.Recent, s.checkpoint.Recent...)
+	}
+	checkpoint.Recent = append(checkpoint.Recent, block)
+	if uint64(len(checkpoint.Recent)) > s.options.ReorgDepth {
+		checkpoint.Recent = checkpoint.Recent[uint64(len(checkpoint.Recent))-s.options.ReorgDepth:]
+	}
+	if err := s.options.Store.Save(ctx, checkpoint); err != nil {
+		return err
+	}
+	s.checkpoint = checkpoint
+	return nil
+}
+
+func (s *Scanner) blockByNumber(ctx context.Context, number uint64) (*types.Block, error) {
+	ctx, cancel := s.chain.WithTimeout(ctx)
+	defer cancel()
+	return s.chain.Client().BlockByNumber(ctx, new(big.Int).SetUint64(number))
+}
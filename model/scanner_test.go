@@ -0,0 +1,215 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chainStub
+//
+//	@Description: a chain of empty blocks, a fork replaces the blocks from a height
+type chainStub struct {
+	lock    sync.Mutex
+	headers []*types.Header
+	txRoot  func(number int) common.Hash // the blocks with txs must not have the empty root
+}
+
+func newChainStub(count int) *chainStub {
+	c := &chainStub{}
+	c.fork(0, count, 0)
+	return c
+}
+
+// fork
+//
+//	@Description: replace the blocks from the height, the chain has count blocks after it
+//	@receiver c
+//	@param from
+//	@param count
+//	@param branch makes the hashes of the new blocks different
+func (c *chainStub) fork(from, count int, branch byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.headers = c.headers[:from]
+	for i := from; i < count; i++ {
+		header := &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Difficulty: common.Big0,
+			UncleHash:  types.EmptyUncleHash,
+			TxHash:     types.EmptyTxsHash,
+			Extra:      []byte{branch},
+		}
+		if c.txRoot != nil {
+			header.TxHash = c.txRoot(i)
+		}
+		if i > 0 {
+			header.ParentHash = c.headers[i-1].Hash()
+		}
+		c.headers = append(c.headers, header)
+	}
+}
+
+func (c *chainStub) hash(number int) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.headers[number].Hash().Hex()
+}
+
+func (c *chainStub) ChainId() (hexutil.Big, error) {
+	return hexutil.Big(*big.NewInt(1)), nil
+}
+
+func (c *chainStub) BlockNumber() (hexutil.Uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return hexutil.Uint64(len(c.headers) - 1), nil
+}
+
+func (c *chainStub) GetBlockByNumber(number string, _ bool) (map[string]interface{}, error) {
+	n, err := hexutil.DecodeUint64(number)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if n >= uint64(len(c.headers)) {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(c.headers[n])
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err = json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	result["transactions"] = []interface{}{}
+	result["uncles"] = []interface{}{}
+	return result, nil
+}
+
+func TestScanner(t *testing.T) {
+	stub := newChainStub(10)
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	var (
+		handled    []uint64
+		rolledBack []uint64
+	)
+	options := ScannerOptions{
+		StartBlock: 1,
+		Store:      store,
+		OnBlock: func(ctx context.Context, block *types.Block) error {
+			handled = append(handled, block.NumberU64())
+			return nil
+		},
+		OnRollback: func(ctx context.Context, number uint64) error {
+			rolledBack = append(rolledBack, number)
+			return nil
+		},
+	}
+	chain := newStubChain(t, stub)
+	scanner := NewScanner(chain, options)
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9}, handled)
+	require.Equal(t, uint64(9), scanner.Checkpoint().Number)
+	require.Equal(t, stub.hash(9), scanner.Checkpoint().Hash)
+
+	// the blocks from 7 are replaced, roll back to 6
+	handled = nil
+	stub.fork(7, 12, 1)
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, []uint64{6}, rolledBack)
+	require.Equal(t, []uint64{7, 8, 9, 10, 11}, handled)
+	require.Equal(t, stub.hash(11), scanner.Checkpoint().Hash)
+
+	// a new scanner resumes from the saved checkpoint
+	handled = nil
+	stub.fork(12, 14, 1)
+	scanner = NewScanner(chain, options)
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, []uint64{12, 13}, handled)
+
+	// the confirmations, the head 16 has 1, 14 has 3
+	handled = nil
+	stub.fork(14, 17, 1)
+	options.Confirmations = 3
+	scanner = NewScanner(chain, options)
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, []uint64{14}, handled)
+	handled = nil
+	options.Confirmations = 2
+	scanner = NewScanner(chain, options)
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, []uint64{15}, handled)
+
+	// the reorg is deeper than the recent blocks
+	options.ReorgDepth = 2
+	options.Confirmations = 0
+	scanner = NewScanner(chain, options)
+	require.Nil(t, scanner.Scan(context.Background()))
+	stub.fork(10, 20, 2)
+	err := scanner.Scan(context.Background())
+	require.True(t, errors.Is(err, ErrReorgTooDeep))
+}
+
+func TestScannerHandlerError(t *testing.T) {
+	stub := newChainStub(5)
+	failed := false
+	scanner := NewScanner(newStubChain(t, stub), ScannerOptions{
+		OnBlock: func(ctx context.Context, block *types.Block) error {
+			if block.NumberU64() == 3 && !failed {
+				failed = true
+				return errors.New("db is down")
+			}
+			return nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := scanner.Run(ctx)
+	var handlerErr *ScanHandlerError
+	require.True(t, errors.As(err, &handlerErr))
+	require.Equal(t, uint64(3), handlerErr.Number)
+	require.Equal(t, uint64(2), scanner.Checkpoint().Number)
+
+	// the failed block is scanned again
+	require.Nil(t, scanner.Scan(context.Background()))
+	require.Equal(t, uint64(4), scanner.Checkpoint().Number)
+}
+
+func TestScannerNodeError(t *testing.T) {
+	stub := &blockReceiptsStub{depositStub: newDepositStub(t), fails: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	var txs []string
+	scanner := NewScanner(newStubChain(t, stub), ScannerOptions{
+		StartBlock: 1,
+		OnTransaction: func(ctx context.Context, tx *Transaction) error {
+			txs = append(txs, tx.Hash)
+			return nil
+		},
+		OnBlock: func(ctx context.Context, block *types.Block) error {
+			if block.NumberU64() == 5 {
+				cancel()
+			}
+			return nil
+		},
+	})
+
+	// the failed call is not a handler error, the block is scanned again on the next head
+	err := scanner.Run(ctx)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, int32(2), stub.calls.Load())
+	require.Equal(t, []string{stub.txs[2].Hash().Hex()}, txs)
+	require.Equal(t, uint64(5), scanner.Checkpoint().Number)
+}
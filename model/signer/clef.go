@@ -0,0 +1,194 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+)
+
+// ClefSigner
+//
+//	@Description: sign with an external signer speaking the clef json-rpc protocol (account_signTransaction etc.)
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+type clefSignTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewClefSigner
+//
+//	@Description: connect the clef endpoint, eg: http://localhost:8550 or the ipc path
+//	@param ctx
+//	@param endpoint
+//	@param address the account managed by clef
+//	@return *ClefSigner
+//	@return error
+func NewClefSigner(ctx context.Context, endpoint, address string) (*ClefSigner, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid hex address")
+	}
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewClefSignerFromClient(client, address), nil
+}
+
+func NewClefSignerFromClient(client *rpc.Client, address string) *ClefSigner {
+	return &ClefSigner{
+		client:  client,
+		address: common.HexToAddress(address),
+	}
+}
+
+func (s *ClefSigner) Close() {
+	s.client.Close()
+}
+
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash
+//
+//	@Description: clef never signs a raw hash
+//	@receiver s
+//	@return error ErrUnsupported
+func (s *ClefSigner) SignHash(_ context.Context, _ []byte) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func (s *ClefSigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := s.client.CallContext(ctx, &signature, "account_signData", apitypes.TextPlain.Mime, common.NewMixedcaseAddress(s.address), hexutil.Encode(text))
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
+
+func (s *ClefSigner) SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		t := common.NewMixedcaseAddress(*tx.To())
+		to = &t
+	}
+	args := &apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(s.address),
+		To:    to,
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  &data,
+	}
+	if chainId != nil {
+		args.ChainID = (*hexutil.Big)(chainId)
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
+	}
+
+	var result clefSignTxResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(result.Raw); err != nil {
+		return nil, err
+	}
+	if err := s.checkSignedTx(tx, signedTx, chainId); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// checkSignedTx
+//
+//	@Description: the external signer must sign the requested tx by the account, not a substituted one
+//	@receiver s
+//	@param tx the requested tx
+//	@param signedTx the tx signed by clef
+//	@param chainId
+//	@return error
+func (s *ClefSigner) checkSignedTx(tx, signedTx *types.Transaction, chainId *big.Int) error {
+	if signedTx.Type() != tx.Type() ||
+		signedTx.Nonce() != tx.Nonce() ||
+		signedTx.Gas() != tx.Gas() ||
+		signedTx.Value().Cmp(tx.Value()) != 0 ||
+		!bytes.Equal(signedTx.Data(), tx.Data()) ||
+		signedTx.GasPrice().Cmp(tx.GasPrice()) != 0 ||
+		signedTx.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 ||
+		signedTx.GasTipCap().Cmp(tx.GasTipCap()) != 0 ||
+		!equalAccessList(signedTx.AccessList(), tx.AccessList()) {
+		return errors.New("the tx signed by clef doesn't match the request")
+	}
+	if (signedTx.To() == nil) != (tx.To() == nil) || (tx.To() != nil && *signedTx.To() != *tx.To()) {
+		return errors.New("the tx signed by clef has another receiver")
+	}
+	if chainId == nil {
+		chainId = signedTx.ChainId()
+	} else if signedTx.Protected() && signedTx.ChainId().Cmp(chainId) != 0 {
+		return errors.New("the tx signed by clef has another chain id")
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	if err != nil {
+		return err
+	}
+	if from != s.address {
+		return fmt.Errorf("the tx is signed by %s, not %s", from.Hex(), s.address.Hex())
+	}
+	return nil
+}
+
+func equalAccessList(a, b types.AccessList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Address != b[i].Address || len(a[i].StorageKeys) != len(b[i].StorageKeys) {
+			return false
+		}
+		for j := range a[i].StorageKeys {
+			if a[i].StorageKeys[j] != b[i].StorageKeys[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *ClefSigner) SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error) {
+	if typedData == nil {
+		return nil, errors.New("typed data is empty")
+	}
+	var signature hexutil.Bytes
+	err := s.client.CallContext(ctx, &signature, "account_signTypedData", common.NewMixedcaseAddress(s.address), typedData)
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
@@ -0,0 +1,105 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+)
+
+// KeystoreSigner
+//
+//	@Description: sign with a go-ethereum keystore account, the key is unlocked by password and kept by the keystore
+type KeystoreSigner struct {
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+// NewKeystoreSigner
+//
+//	@Description: open the keystore dir and unlock the account
+//	@param keystoreDir
+//	@param address the account in the keystore dir
+//	@param password
+//	@return *KeystoreSigner
+//	@return error
+func NewKeystoreSigner(keystoreDir, address, password string) (*KeystoreSigner, error) {
+	ks := keystore.NewKeyStore(keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	return NewKeystoreSignerFromKeyStore(ks, address, password)
+}
+
+// NewKeystoreSignerFromKeyStore
+//
+//	@Description: unlock the account of an opened keystore
+//	@param ks
+//	@param address
+//	@param password
+//	@return *KeystoreSigner
+//	@return error
+func NewKeystoreSignerFromKeyStore(ks *keystore.KeyStore, address, password string) (*KeystoreSigner, error) {
+	if ks == nil {
+		return nil, errors.New("keystore is empty")
+	}
+	if !common.IsHexAddress(address) {
+		return nil, errors.New("invalid hex address")
+	}
+	account, err := ks.Find(accounts.Account{Address: common.HexToAddress(address)})
+	if err != nil {
+		return nil, err
+	}
+	if err = ks.Unlock(account, password); err != nil {
+		return nil, err
+	}
+	return &KeystoreSigner{
+		ks:      ks,
+		account: account,
+	}, nil
+}
+
+// Lock
+//
+//	@Description: remove the unlocked key from memory, the signer can't sign any more
+//	@receiver s
+//	@return error
+func (s *KeystoreSigner) Lock() error {
+	return s.ks.Lock(s.account.Address)
+}
+
+func (s *KeystoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *KeystoreSigner) SignHash(_ context.Context, hash []byte) ([]byte, error) {
+	return s.ks.SignHash(s.account, hash)
+}
+
+func (s *KeystoreSigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	signature, err := s.SignHash(ctx, accounts.TextHash(text))
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
+
+func (s *KeystoreSigner) SignTx(_ context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return s.ks.SignTx(s.account, tx, chainId)
+}
+
+func (s *KeystoreSigner) SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error) {
+	if typedData == nil {
+		return nil, errors.New("typed data is empty")
+	}
+	hash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.SignHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
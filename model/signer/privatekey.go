@@ -0,0 +1,79 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+)
+
+// PrivateKeySigner
+//
+//	@Description: sign with the private key in memory
+type PrivateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewPrivateKeySigner(privateKey string) (*PrivateKeySigner, error) {
+	priData, err := util.HexDecodeString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKeyECDSA, err := crypto.ToECDSA(priData)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySignerFromECDSA(privateKeyECDSA)
+}
+
+func NewPrivateKeySignerFromECDSA(privateKey *ecdsa.PrivateKey) (*PrivateKeySigner, error) {
+	if privateKey == nil {
+		return nil, errors.New("private key is empty")
+	}
+	return &PrivateKeySigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}, nil
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *PrivateKeySigner) SignHash(_ context.Context, hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.privateKey)
+}
+
+func (s *PrivateKeySigner) SignText(ctx context.Context, text []byte) ([]byte, error) {
+	signature, err := s.SignHash(ctx, accounts.TextHash(text))
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
+
+func (s *PrivateKeySigner) SignTx(_ context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainId), s.privateKey)
+}
+
+func (s *PrivateKeySigner) SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error) {
+	if typedData == nil {
+		return nil, errors.New("typed data is empty")
+	}
+	hash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.SignHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return toEthereumV(signature), nil
+}
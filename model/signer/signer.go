@@ -0,0 +1,40 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+)
+
+var ErrUnsupported = errors.New("the operation is not supported by this signer")
+
+// Signer
+//
+//	@Description: sign with a key that may live outside the process, eg: keystore, clef
+type Signer interface {
+	// Address the address of the key
+	Address() common.Address
+	// SignHash sign a 32 bytes hash, the signature is [R || S || V] and V is 0 or 1
+	SignHash(ctx context.Context, hash []byte) ([]byte, error)
+	// SignText sign a message with the "\x19Ethereum Signed Message:\n" prefix (personal_sign), V is 27 or 28
+	SignText(ctx context.Context, text []byte) ([]byte, error)
+	// SignTx sign the transaction for chainId
+	SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+	// SignTypedData sign eip712 typed data, V is 27 or 28
+	SignTypedData(ctx context.Context, typedData *apitypes.TypedData) ([]byte, error)
+}
+
+// toEthereumV
+//
+//	@Description: transform V from 0/1 to 27/28
+//	@param signature
+//	@return []byte
+func toEthereumV(signature []byte) []byte {
+	if len(signature) == 65 && signature[64] < 27 {
+		signature[64] += 27
+	}
+	return signature
+}
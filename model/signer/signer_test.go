@@ -0,0 +1,222 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	testPrivateKey = "1f9cea18b76799f950f1b920579ba44a0ebc65c56f8bcd740405efcc4cf11b0f"
+	testAddress    = "0x7a547A149A79A03F4dd441B6806ffCBb1b63F383"
+)
+
+// clefStub
+//
+//	@Description: a local stub of the clef "account" namespace
+type clefStub struct {
+	key    *ecdsa.PrivateKey
+	tamper func(args *apitypes.SendTxArgs) // a misbehaving clef signs another tx
+}
+
+func (c *clefStub) SignTransaction(_ context.Context, args apitypes.SendTxArgs, _ *string) (map[string]interface{}, error) {
+	if c.tamper != nil {
+		c.tamper(&args)
+	}
+	tx, err := types.SignTx(args.ToTransaction(), types.LatestSignerForChainID(args.ChainID.ToInt()), c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+func (c *clefStub) SignData(_ context.Context, _ string, _ common.MixedcaseAddress, data string) (hexutil.Bytes, error) {
+	text, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(accounts.TextHash(text), c.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func (c *clefStub) SignTypedData(_ context.Context, _ common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(hash, c.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += 27
+	return signature, nil
+}
+
+func testTypedData() *apitypes.TypedData {
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+			},
+			"Action": {
+				{Name: "actionType", Type: "string"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Action",
+		Domain: apitypes.TypedDataDomain{
+			Name:    "Test",
+			Version: "1",
+		},
+		Message: map[string]interface{}{
+			"actionType": "LOGIN",
+			"nonce":      "1",
+		},
+	}
+}
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x8B63293748e058F47a31c0D2Af0B1b3FeDdc4D4C")
+	return types.NewTx(&types.DynamicFeeTx{
+		Nonce:     1,
+		To:        &to,
+		Value:     big.NewInt(1000),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2000000000),
+		GasTipCap: big.NewInt(1000000000),
+	})
+}
+
+// checkSigner
+//
+//	@Description: every signer must produce the same signatures as the private key
+//	@param t
+//	@param s
+func checkSigner(t *testing.T, s Signer) {
+	ctx := context.Background()
+	expect, err := NewPrivateKeySigner(testPrivateKey)
+	require.Nil(t, err)
+	require.Equal(t, testAddress, s.Address().Hex())
+
+	chainId := big.NewInt(17000)
+	signedTx, err := s.SignTx(ctx, testTx(), chainId)
+	require.Nil(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), signedTx)
+	require.Nil(t, err)
+	require.Equal(t, testAddress, from.Hex())
+
+	signature, err := s.SignTypedData(ctx, testTypedData())
+	require.Nil(t, err)
+	expectSignature, err := expect.SignTypedData(ctx, testTypedData())
+	require.Nil(t, err)
+	require.Equal(t, expectSignature, signature)
+
+	signature, err = s.SignText(ctx, []byte("hello"))
+	require.Nil(t, err)
+	expectSignature, err = expect.SignText(ctx, []byte("hello"))
+	require.Nil(t, err)
+	require.Equal(t, expectSignature, signature)
+}
+
+func TestPrivateKeySigner(t *testing.T) {
+	s, err := NewPrivateKeySigner("0x" + testPrivateKey)
+	require.Nil(t, err)
+	checkSigner(t, s)
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.Nil(t, err)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	_, err = ks.ImportECDSA(key, "123456")
+	require.Nil(t, err)
+
+	_, err = NewKeystoreSignerFromKeyStore(ks, testAddress, "654321")
+	require.NotNil(t, err)
+
+	s, err := NewKeystoreSignerFromKeyStore(ks, testAddress, "123456")
+	require.Nil(t, err)
+	checkSigner(t, s)
+
+	require.Nil(t, s.Lock())
+	_, err = s.SignHash(context.Background(), crypto.Keccak256([]byte("hello")))
+	require.NotNil(t, err)
+}
+
+func TestClefSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.Nil(t, err)
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.Nil(t, server.RegisterName("account", &clefStub{key: key}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	s, err := NewClefSigner(context.Background(), httpServer.URL, testAddress)
+	require.Nil(t, err)
+	defer s.Close()
+	checkSigner(t, s)
+
+	_, err = s.SignHash(context.Background(), crypto.Keccak256([]byte("hello")))
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestClefSignerSubstitutedTx(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	require.Nil(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	stub := &clefStub{key: key}
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.Nil(t, server.RegisterName("account", stub))
+	s := NewClefSignerFromClient(rpc.DialInProc(server), testAddress)
+	defer s.Close()
+
+	tampers := []func(args *apitypes.SendTxArgs){
+		func(args *apitypes.SendTxArgs) { args.Nonce++ },
+		func(args *apitypes.SendTxArgs) { args.Value = hexutil.Big(*big.NewInt(1)) },
+		func(args *apitypes.SendTxArgs) {
+			to := common.NewMixedcaseAddress(common.HexToAddress(testAddress))
+			args.To = &to
+		},
+		func(args *apitypes.SendTxArgs) { args.Gas++ },
+		func(args *apitypes.SendTxArgs) { args.MaxFeePerGas = (*hexutil.Big)(big.NewInt(9000000000)) },
+		func(args *apitypes.SendTxArgs) {
+			data := hexutil.Bytes{1}
+			args.Data = &data
+		},
+		func(args *apitypes.SendTxArgs) { args.ChainID = (*hexutil.Big)(big.NewInt(1)) },
+	}
+	for i, tamper := range tampers {
+		stub.tamper = tamper
+		_, err = s.SignTx(context.Background(), testTx(), big.NewInt(17000))
+		require.NotNil(t, err, "tamper %d", i)
+	}
+
+	// signed by another account
+	stub.tamper = nil
+	stub.key = otherKey
+	_, err = s.SignTx(context.Background(), testTx(), big.NewInt(17000))
+	require.NotNil(t, err)
+}
@@ -3,11 +3,13 @@ package model
 import (
 	"context"
 	"errors"
+	"github.com/bitxx/evm-utils/model/signer"
 	"github.com/bitxx/evm-utils/model/types"
 	"github.com/bitxx/evm-utils/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"time"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
 )
 
 type Token struct {
@@ -21,6 +23,10 @@ func NewToken(chain *Chain) *Token {
 }
 
 func (t *Token) BalanceOf(address string) (balance string, err error) {
+	return t.BalanceOfCtx(context.Background(), address)
+}
+
+func (t *Token) BalanceOfCtx(ctx context.Context, address string) (balance string, err error) {
 	if t.chain == nil {
 		return "", errors.New("the chain node is empty")
 	}
@@ -28,9 +34,9 @@ func (t *Token) BalanceOf(address string) (balance string, err error) {
 		return "", errors.New("invalid hex address")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
+	ctx, cancel := t.chain.WithTimeout(ctx)
 	defer cancel()
-	balanceResult, err := t.chain.RemoteRpcClient.BalanceAt(ctx, common.HexToAddress(address), nil)
+	balanceResult, err := t.chain.Client().BalanceAt(ctx, common.HexToAddress(address), nil)
 	if err != nil {
 		return "", err
 	}
@@ -38,35 +44,183 @@ func (t *Token) BalanceOf(address string) (balance string, err error) {
 }
 
 func (t *Token) Transfer(privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
-	if gasPrice == "" || gasLimit == "" || to == "" || value == "" {
-		return "", errors.New("param is error")
-	}
-	tx := types.NewTransaction(nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, value, data)
+	return t.TransferCtx(context.Background(), privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
 
-	priData, err := util.HexDecodeString(privateKey)
+func (t *Token) TransferCtx(ctx context.Context, privateKey, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	s, err := signer.NewPrivateKeySigner(privateKey)
 	if err != nil {
 		return "", err
 	}
-	privateKeyECDSA, err := crypto.ToECDSA(priData)
-	address := crypto.PubkeyToAddress(privateKeyECDSA.PublicKey).Hex()
+	return t.TransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
+
+func (t *Token) TransferWithSigner(s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	return t.TransferWithSignerCtx(context.Background(), s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+}
+
+// TransferWithSignerCtx
+//
+//	@Description: transfer and sign the transaction with any signer, if gasPrice is empty, the fees are filled by the fee oracle
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
+//	@param to
+//	@param data
+//	@return hash
+//	@return err
+func (t *Token) TransferWithSignerCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (hash string, err error) {
+	if to == "" {
+		return "", errors.New("param is error")
+	}
+	result, err := t.transferWithSigner(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data)
+	if result == nil {
+		return "", err
+	}
+	return result.TxHex, err
+}
+
+// transferWithSigner
+//
+//	@Description: the same as TransferWithSignerCtx, but the empty to is a contract creation, the signed tx is returned
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value
+//	@param to
+//	@param data
+//	@return result the signed tx, it is not nil when the tx is signed but not sent
+//	@return err
+func (t *Token) transferWithSigner(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, to, data string) (result *types.BuildTxResult, err error) {
+	if s == nil || gasLimit == "" || value == "" {
+		return nil, errors.New("param is error")
+	}
+	if gasPrice == "" {
+		gasPrice, maxPriorityFeePerGas, err = t.fillFees(ctx, maxPriorityFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the nonce is handed out by the nonce manager when it is empty
+	managed := nonce == "" || nonce == "0"
+	address := s.Address().Hex()
+	result, err = t.sendWithSigner(ctx, s, types.NewTransaction(nonce, gasPrice, gasLimit, maxPriorityFeePerGas, to, value, data))
+	if !managed || err == nil {
+		return result, err
+	}
+	if IsAlreadyKnownError(err) {
+		// the same tx has been sent
+		return result, nil
+	}
+	if IsNonceError(err) || IsNonceTooHighError(err) {
+		// the nonce was used by a tx not sent by the manager, or a lower nonce never reached the node, retry once with a reconciled one
+		if err = t.chain.NonceManager().Resync(ctx, address); err != nil {
+			return nil, err
+		}
+		return t.sendWithSigner(ctx, s, types.NewTransaction("", gasPrice, gasLimit, maxPriorityFeePerGas, to, value, data))
+	}
+	return result, err
+}
+
+// sendWithSigner
+//
+//	@Description: build, sign and send the tx, the nonce got from the nonce manager is released if the tx is surely not sent,
+//	it is kept on the ambiguous errors like timeouts, the next Resync hands it out again if the node never got the tx
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param tx
+//	@return result the signed tx
+//	@return err
+func (t *Token) sendWithSigner(ctx context.Context, s signer.Signer, tx *types.Transaction) (result *types.BuildTxResult, err error) {
+	address := s.Address().Hex()
+	managed := tx.Nonce == "" || tx.Nonce == "0"
 
 	//get no sign tx
-	txUnSign, err := t.chain.BuildTxUnSign(address, tx)
+	txUnSign, err := t.chain.BuildTxUnSignCtx(ctx, address, tx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	//tx sign
-	txSign, err := t.chain.BuildTxSign(privateKeyECDSA, txUnSign)
+	result, err = t.chain.BuildTxSignWithSigner(ctx, s, txUnSign)
 	if err != nil {
-		return "", err
+		if managed {
+			t.chain.NonceManager().Release(address, txUnSign.Nonce())
+		}
+		return nil, err
 	}
 
 	//send tx
-	return txSign.TxHex, t.chain.SendTx(txSign.SignedTx)
+	err = t.chain.SendTxCtx(ctx, result.SignedTx)
+	if managed {
+		if isRejectedError(err) {
+			t.chain.NonceManager().Release(address, txUnSign.Nonce())
+		} else {
+			t.chain.NonceManager().Sent(address, txUnSign.Nonce())
+		}
+	}
+	return result, err
+}
+
+// sendContractCtx
+//
+//	@Description: pack the call of the contract method, estimate the gas limit if it is empty, then send it like a transfer
+//	@receiver t
+//	@param ctx
+//	@param s
+//	@param nonce
+//	@param gasPrice
+//	@param gasLimit
+//	@param maxPriorityFeePerGas
+//	@param value the wei sent to a payable method, "0" if not payable
+//	@param contractAddress
+//	@param contractAbi
+//	@param method
+//	@param args
+//	@return hash
+//	@return err the revert is decoded by contractAbi
+func (t *Token) sendContractCtx(ctx context.Context, s signer.Signer, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress string, contractAbi *abi.ABI, method string, args ...interface{}) (hash string, err error) {
+	if s == nil || contractAbi == nil {
+		return "", errors.New("param is error")
+	}
+	if !util.IsValidAddress(contractAddress) {
+		return "", errors.New("contract address format is error")
+	}
+	data, err := contractAbi.Pack(method, args...)
+	if err != nil {
+		return "", err
+	}
+	if gasLimit == "" {
+		gasLimit, err = t.EstimateGasLimitCtx(ctx, s.Address().Hex(), contractAddress, gasPrice, value, data)
+		if err != nil {
+			// the custom errors of the contract are only known by contractAbi
+			var revertErr *RevertError
+			if errors.As(err, &revertErr) && len(revertErr.Data) > 0 {
+				decoded := DecodeRevert(revertErr.Data, contractAbi)
+				decoded.Err = revertErr.Err
+				return "", decoded
+			}
+			return "", err
+		}
+	}
+	return t.TransferWithSignerCtx(ctx, s, nonce, gasPrice, gasLimit, maxPriorityFeePerGas, value, contractAddress, hexutil.Encode(data))
 }
 
 func (t *Token) EstimateGasLimit(fromAddress, receiverAddress, gasPrice, amount string, data []byte) (string, error) {
+	return t.EstimateGasLimitCtx(context.Background(), fromAddress, receiverAddress, gasPrice, amount, data)
+}
+
+func (t *Token) EstimateGasLimitCtx(ctx context.Context, fromAddress, receiverAddress, gasPrice, amount string, data []byte) (string, error) {
 	msg := types.NewCallMsg()
 	msg.SetFrom(fromAddress)
 	msg.SetTo(receiverAddress)
@@ -75,5 +229,35 @@ func (t *Token) EstimateGasLimit(fromAddress, receiverAddress, gasPrice, amount
 	if data != nil {
 		msg.SetData(data)
 	}
-	return t.chain.EstimateGasLimit(msg)
+	return t.chain.EstimateGasLimitCtx(ctx, msg)
+}
+
+// fillFees
+//
+//	@Description: fill the empty fees with the standard speed of the fee oracle
+//	@receiver t
+//	@param ctx
+//	@param maxPriorityFeePerGas keep it if it is not empty
+//	@return gasPrice maxFeePerGas for eip1559
+//	@return priorityFee
+//	@return err
+func (t *Token) fillFees(ctx context.Context, maxPriorityFeePerGas string) (gasPrice, priorityFee string, err error) {
+	fees, err := t.chain.EstimateFeesCtx(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if fees.IsLegacy || maxPriorityFeePerGas == "" {
+		return fees.Standard.MaxFeePerGas, fees.Standard.MaxPriorityFeePerGas, nil
+	}
+
+	// the caller's tip on top of the oracle's base fee part
+	tip, ok := new(big.Int).SetString(maxPriorityFeePerGas, 10)
+	if !ok {
+		return "", "", errors.New("invalid max priority fee per gas")
+	}
+	maxFee, _ := new(big.Int).SetString(fees.Standard.MaxFeePerGas, 10)
+	oracleTip, _ := new(big.Int).SetString(fees.Standard.MaxPriorityFeePerGas, 10)
+	maxFee.Sub(maxFee, oracleTip)
+	maxFee.Add(maxFee, tip)
+	return maxFee.String(), maxPriorityFeePerGas, nil
 }
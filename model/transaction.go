@@ -2,12 +2,23 @@ package model
 
 import (
 	"context"
+	"fmt"
+	"github.com/bitxx/evm-utils/config"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/shopspring/decimal"
 	"math/big"
 	"strconv"
-	"time"
+	"sync"
+)
+
+type TxStatus string
+
+const (
+	TxStatusSuccess  TxStatus = "success"
+	TxStatusReverted TxStatus = "reverted"
+	TxStatusDropped  TxStatus = "dropped"  // the tx disappeared from the node before it was mined
+	TxStatusReplaced TxStatus = "replaced" // another tx with the same nonce was mined
 )
 
 type Transaction struct {
@@ -35,6 +46,9 @@ type Transaction struct {
 	BlobGasPrice      decimal.Decimal
 	TransactionIndex  uint
 	ContractAddress   string
+	BlockNumber       uint64
+	BlockHash         string
+	Status            TxStatus
 
 	//TODO blobs待定
 
@@ -55,30 +69,36 @@ func NewTransaction(chain *Chain) *Transaction {
 //	@return *types.Block
 //	@return error
 func (t *Transaction) BlockByNumber(number uint64) (*types.Block, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
+	return t.BlockByNumberCtx(context.Background(), number)
+}
+
+func (t *Transaction) BlockByNumberCtx(ctx context.Context, number uint64) (*types.Block, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
 	defer cancel()
 	if number <= 0 {
-		return t.chain.RemoteRpcClient.BlockByNumber(ctx, nil)
+		return t.chain.Client().BlockByNumber(ctx, nil)
 	}
-	return t.chain.RemoteRpcClient.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	return t.chain.Client().BlockByNumber(ctx, new(big.Int).SetUint64(number))
 }
 
 // BlockReceiptsByNumber
 //
-//	@Description: 读取一个块交易的回执
+//	@Description: 读取一个块交易的回执，优先使用 eth_getBlockReceipts，节点不支持时批量读取 eth_getTransactionReceipt
 //	@receiver t
-//	@param number
-//	@return []*types.Receipt
+//	@param number 如果number<=0，则读取最新块
+//	@return []*types.Receipt in the order of the txs
 //	@return error
-/*func (t *Transaction) BlockReceiptsByNumber(number uint64) ([]*types.Receipt, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
-	defer cancel()
-	if number <= 0 {
-		return t.chain.RemoteRpcClient.BlockReceipts(ctx, rpc.BlockNumberOrHash{})
+func (t *Transaction) BlockReceiptsByNumber(number uint64) ([]*types.Receipt, error) {
+	return t.BlockReceiptsByNumberCtx(context.Background(), number)
+}
+
+func (t *Transaction) BlockReceiptsByNumberCtx(ctx context.Context, number uint64) ([]*types.Receipt, error) {
+	block, err := t.BlockByNumberCtx(ctx, number)
+	if err != nil {
+		return nil, err
 	}
-	n := rpc.BlockNumber(1)
-	return t.chain.RemoteRpcClient.BlockReceipts(ctx, rpc.BlockNumberOrHash{BlockNumber: &n, RequireCanonical: false})
-}*/
+	return t.chain.blockReceipts(ctx, block)
+}
 
 // TxByHash
 //
@@ -88,10 +108,14 @@ func (t *Transaction) BlockByNumber(number uint64) (*types.Block, error) {
 //	@return *types.Receipt
 //	@return error
 func (t *Transaction) TxByHash(hash string) (*Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
+	return t.TxByHashCtx(context.Background(), hash)
+}
+
+func (t *Transaction) TxByHashCtx(ctx context.Context, hash string) (*Transaction, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
 	defer cancel()
 
-	tx, _, err := t.chain.RemoteRpcClient.TransactionByHash(ctx, common.HexToHash(hash))
+	tx, _, err := t.chain.Client().TransactionByHash(ctx, common.HexToHash(hash))
 	if err != nil {
 		return nil, err
 	}
@@ -99,32 +123,37 @@ func (t *Transaction) TxByHash(hash string) (*Transaction, error) {
 
 }
 
-func (t *Transaction) parseTx(ctx context.Context, tx *types.Transaction, block *types.Block) (*Transaction, error) {
+func (t *Transaction) parseTx(ctx context.Context, tx *types.Transaction, header *types.Header) (*Transaction, error) {
 
-	receipt, err := t.chain.RemoteRpcClient.TransactionReceipt(ctx, tx.Hash())
+	receipt, err := t.chain.Client().TransactionReceipt(ctx, tx.Hash())
 	if err != nil {
 		return nil, err
 	}
+	return t.parseTxWithReceipt(ctx, tx, receipt, header)
+}
 
-	if block == nil {
-		block, err = t.BlockByNumber(receipt.BlockNumber.Uint64())
+// parseTxWithReceipt
+//
+//	@Description: the tx with its receipt, only the time of the block is needed
+//	@receiver t
+//	@param ctx
+//	@param tx
+//	@param receipt
+//	@param header the header of the block, it is fetched without the txs if nil
+//	@return *Transaction
+//	@return error
+func (t *Transaction) parseTxWithReceipt(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, header *types.Header) (*Transaction, error) {
+	var err error
+	if header == nil {
+		callCtx, cancel := t.chain.WithTimeout(ctx)
+		header, err = t.chain.Client().HeaderByHash(callCtx, receipt.BlockHash)
+		cancel()
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	var signer types.Signer
-	switch {
-	case tx.Type() == types.AccessListTxType:
-		signer = types.NewEIP2930Signer(tx.ChainId())
-	case tx.Type() == types.DynamicFeeTxType:
-		signer = types.NewLondonSigner(tx.ChainId())
-	case tx.Type() == types.BlobTxType:
-		signer = types.NewCancunSigner(tx.ChainId())
-	default:
-		signer = types.NewEIP155Signer(tx.ChainId())
-	}
-	from, err := types.Sender(signer, tx)
+	from, err := txSender(tx)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +189,7 @@ func (t *Transaction) parseTx(ctx context.Context, tx *types.Transaction, block
 		GasTipCap:         decimal.NewFromBigInt(tx.GasTipCap(), 0),
 		To:                to,
 		From:              from.String(),
-		Time:              block.Time(),
+		Time:              header.Time,
 		GasUsed:           receipt.GasUsed,
 		CumulativeGasUsed: receipt.CumulativeGasUsed,
 		ReceiptStatus:     receipt.Status,
@@ -169,9 +198,40 @@ func (t *Transaction) parseTx(ctx context.Context, tx *types.Transaction, block
 		BlobGasPrice:      decimal.NewFromBigInt(blobGasPrice, 0),
 		TransactionIndex:  receipt.TransactionIndex,
 		ContractAddress:   receipt.ContractAddress.Hex(),
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		BlockHash:         receipt.BlockHash.Hex(),
+		Status:            receiptStatus(receipt),
 	}, nil
 }
 
+// txSender
+//
+//	@Description: recover the sender of the transaction
+//	@param tx
+//	@return common.Address
+//	@return error
+func txSender(tx *types.Transaction) (common.Address, error) {
+	var signer types.Signer
+	switch {
+	case tx.Type() == types.AccessListTxType:
+		signer = types.NewEIP2930Signer(tx.ChainId())
+	case tx.Type() == types.DynamicFeeTxType:
+		signer = types.NewLondonSigner(tx.ChainId())
+	case tx.Type() == types.BlobTxType:
+		signer = types.NewCancunSigner(tx.ChainId())
+	default:
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	return types.Sender(signer, tx)
+}
+
+func receiptStatus(receipt *types.Receipt) TxStatus {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return TxStatusSuccess
+	}
+	return TxStatusReverted
+}
+
 // TxByBlockNumber
 //
 //	@Description: 获取一个块的交易
@@ -180,18 +240,22 @@ func (t *Transaction) parseTx(ctx context.Context, tx *types.Transaction, block
 //	@return []Transaction
 //	@return error
 func (t *Transaction) TxByBlockNumber(number uint64) ([]Transaction, error) {
-	block, err := t.BlockByNumber(number)
+	return t.TxByBlockNumberCtx(context.Background(), number)
+}
+
+func (t *Transaction) TxByBlockNumberCtx(ctx context.Context, number uint64) ([]Transaction, error) {
+	block, err := t.BlockByNumberCtx(ctx, number)
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
-	defer cancel()
-	var transactions []Transaction
-	for _, tx := range block.Transactions() {
-		if tx.Hash().Hex() == "" {
-			continue
-		}
-		transaction, err := t.parseTx(ctx, tx, block)
+	receipts, err := t.chain.blockReceipts(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]Transaction, 0, len(block.Transactions()))
+	header := block.Header()
+	for i, tx := range block.Transactions() {
+		transaction, err := t.parseTxWithReceipt(ctx, tx, receipts[i], header)
 		if err != nil {
 			return nil, err
 		}
@@ -200,6 +264,76 @@ func (t *Transaction) TxByBlockNumber(number uint64) ([]Transaction, error) {
 	return transactions, nil
 }
 
+// TxByBlockRange
+//
+//	@Description: the txs of the blocks from the block to the block, the blocks are fetched concurrently
+//	@receiver t
+//	@param from
+//	@param to
+//	@param concurrency the max blocks fetched at the same time, 0 is config.BlockRangeConcurrency
+//	@return []Transaction in the order of the chain
+//	@return error the first error, the other blocks are cancelled
+func (t *Transaction) TxByBlockRange(from, to uint64, concurrency int) ([]Transaction, error) {
+	return t.TxByBlockRangeCtx(context.Background(), from, to, concurrency)
+}
+
+func (t *Transaction) TxByBlockRangeCtx(ctx context.Context, from, to uint64, concurrency int) ([]Transaction, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid block range: %d - %d", from, to)
+	}
+	if concurrency <= 0 {
+		concurrency = config.BlockRangeConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	blocks := make([][]Transaction, to-from+1)
+	sem := make(chan struct{}, concurrency)
+	for number := from; number <= to; number++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(number uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			transactions, err := t.TxByBlockNumberCtx(ctx, number)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("block %d: %w", number, err)
+					cancel()
+				})
+				return
+			}
+			blocks[number-from] = transactions
+		}(number)
+		if number == to {
+			break
+		}
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	transactions := make([]Transaction, 0)
+	for _, block := range blocks {
+		transactions = append(transactions, block...)
+	}
+	return transactions, nil
+}
+
 // TxIsPending
 //
 //	@Description: is pendding
@@ -208,9 +342,13 @@ func (t *Transaction) TxByBlockNumber(number uint64) ([]Transaction, error) {
 //	@return bool
 //	@return error
 func (t *Transaction) TxIsPending(hash string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
+	return t.TxIsPendingCtx(context.Background(), hash)
+}
+
+func (t *Transaction) TxIsPendingCtx(ctx context.Context, hash string) (bool, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
 	defer cancel()
-	_, isPending, err := t.chain.RemoteRpcClient.TransactionByHash(ctx, common.HexToHash(hash))
+	_, isPending, err := t.chain.Client().TransactionByHash(ctx, common.HexToHash(hash))
 	return isPending, err
 }
 
@@ -221,7 +359,11 @@ func (t *Transaction) TxIsPending(hash string) (bool, error) {
 //	@return uint64
 //	@return error
 func (t *Transaction) LatestBlockNumber() (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.chain.Timeout)*time.Second)
+	return t.LatestBlockNumberCtx(context.Background())
+}
+
+func (t *Transaction) LatestBlockNumberCtx(ctx context.Context) (uint64, error) {
+	ctx, cancel := t.chain.WithTimeout(ctx)
 	defer cancel()
-	return t.chain.RemoteRpcClient.BlockNumber(ctx)
+	return t.chain.Client().BlockNumber(ctx)
 }
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"github.com/bitxx/evm-utils/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockReceiptsStub
+//
+//	@Description: the deposit chain with eth_getBlockReceipts
+type blockReceiptsStub struct {
+	*depositStub
+	calls atomic.Int32
+	fails int32 // the first calls fail like a timeout of the node
+}
+
+func (b *blockReceiptsStub) GetBlockReceipts(block rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	if b.calls.Add(1) <= b.fails {
+		return nil, errors.New("upstream request timeout")
+	}
+	hash, _ := block.Hash()
+	if hash == common.HexToHash(b.hash(2)) {
+		return []*types.Receipt{b.receipts[b.txs[2].Hash()]}, nil
+	}
+	return []*types.Receipt{}, nil
+}
+
+func TestTxByBlockNumber(t *testing.T) {
+	stub := &blockReceiptsStub{depositStub: newDepositStub(t)}
+	chain := newStubChain(t, stub)
+	transactions, err := NewTransaction(chain).TxByBlockNumberCtx(context.Background(), 2)
+	require.Nil(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, stub.txs[2].Hash().Hex(), transactions[0].Hash)
+	require.Equal(t, TxStatusSuccess, transactions[0].Status)
+	require.Equal(t, int32(1), stub.calls.Load())
+	require.Zero(t, chain.noBlockReceipts.Load())
+
+	// no tx, no receipt is fetched
+	transactions, err = NewTransaction(chain).TxByBlockNumberCtx(context.Background(), 3)
+	require.Nil(t, err)
+	require.Len(t, transactions, 0)
+	require.Equal(t, int32(1), stub.calls.Load())
+}
+
+func TestTxByBlockNumberFallback(t *testing.T) {
+	// eth_getBlockReceipts is not supported
+	stub := newDepositStub(t)
+	chain := newStubChain(t, stub)
+	receipts, err := NewTransaction(chain).BlockReceiptsByNumberCtx(context.Background(), 2)
+	require.Nil(t, err)
+	require.Len(t, receipts, 1)
+	require.Equal(t, stub.txs[2].Hash(), receipts[0].TxHash)
+	require.NotZero(t, chain.noBlockReceipts.Load())
+
+	// the tx is included in another block after a reorg, its receipt is not of this block
+	stub.receipts[stub.txs[2].Hash()].BlockHash = common.HexToHash("0x02")
+	_, err = NewTransaction(chain).BlockReceiptsByNumberCtx(context.Background(), 2)
+	require.NotNil(t, err)
+}
+
+func TestBlockReceiptsRetried(t *testing.T) {
+	stub := &blockReceiptsStub{depositStub: newDepositStub(t)}
+	chain := newStubChain(t, stub)
+
+	// the endpoint which didn't support it was long ago, eth_getBlockReceipts is tried again
+	chain.noBlockReceipts.Store(time.Now().Add(-time.Duration(config.BlockReceiptsRetryInterval+1) * time.Second).UnixNano())
+	_, err := NewTransaction(chain).BlockReceiptsByNumberCtx(context.Background(), 2)
+	require.Nil(t, err)
+	require.Equal(t, int32(1), stub.calls.Load())
+	require.Zero(t, chain.noBlockReceipts.Load())
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	require.True(t, isMethodNotFound(errors.New("the method eth_getBlockReceipts does not exist/is not available")))
+	require.True(t, isMethodNotFound(errors.New("Method not found")))
+	require.False(t, isMethodNotFound(errors.New("block 0x1234 does not exist")))
+	require.False(t, isMethodNotFound(errors.New("historical state not supported")))
+}
+
+func TestTxByBlockRange(t *testing.T) {
+	stub := &blockReceiptsStub{depositStub: newDepositStub(t)}
+	transaction := NewTransaction(newStubChain(t, stub))
+	transactions, err := transaction.TxByBlockRangeCtx(context.Background(), 1, 5, 2)
+	require.Nil(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, uint64(2), transactions[0].BlockNumber)
+
+	// block 9 doesn't exist
+	_, err = transaction.TxByBlockRangeCtx(context.Background(), 1, 9, 2)
+	require.NotNil(t, err)
+	_, err = transaction.TxByBlockRangeCtx(context.Background(), 5, 1, 2)
+	require.NotNil(t, err)
+}
@@ -50,6 +50,25 @@ type TransactionByHashResult struct {
 	BlockNumber string // 区块高度
 }
 
+// FeeEstimate
+//
+//	@Description: the fee of one speed, wei
+type FeeEstimate struct {
+	MaxFeePerGas         string // gas price of legacy tx
+	MaxPriorityFeePerGas string // empty if the chain doesn't support eip1559
+}
+
+// FeeSuggestion
+//
+//	@Description: the fees suggested by eth_feeHistory and eth_maxPriorityFeePerGas
+type FeeSuggestion struct {
+	BaseFee  string // base fee of the next block, empty if the chain doesn't support eip1559
+	IsLegacy bool   // the chain doesn't support eip1559, MaxFeePerGas is the gas price
+	Slow     FeeEstimate
+	Standard FeeEstimate
+	Fast     FeeEstimate
+}
+
 type Erc20TxParams struct {
 	ToAddress string `json:"toAddress"`
 	Amount    string `json:"amount"`
@@ -149,12 +168,16 @@ func NewTransactionFromHex(hexData string) (*Transaction, error) {
 	if err != nil {
 		return nil, err
 	}
+	to := ""
+	if decodeTx.To() != nil {
+		to = decodeTx.To().String()
+	}
 	tx := NewTransaction(
 		strconv.Itoa(int(decodeTx.Nonce())),
 		decodeTx.GasFeeCap().String(),
 		strconv.Itoa(int(decodeTx.Gas())),
 		"",
-		decodeTx.To().String(),
+		to,
 		decodeTx.Value().String(),
 		hex.EncodeToString(decodeTx.Data()),
 	)
@@ -181,7 +204,7 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 
 		nonce     uint64 = 0
 		gasLimit  uint64 = 90000 // reference https://eth.wiki/json-rpc/API method eth_sendTransaction
-		toAddress common.Address
+		toAddress *common.Address
 		data      []byte
 		valid     bool
 		err       error
@@ -211,10 +234,14 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 			return nil, errors.New("invalid gas limit")
 		}
 	}
-	if tx.To != "" && !common.IsHexAddress(tx.To) {
-		return nil, errors.New("invalid toAddress")
+	if tx.To != "" {
+		if !common.IsHexAddress(tx.To) {
+			return nil, errors.New("invalid toAddress")
+		}
+		// empty To is a contract creation
+		address := common.HexToAddress(tx.To)
+		toAddress = &address
 	}
-	toAddress = common.HexToAddress(tx.To)
 	if tx.Data != "" {
 		if data, err = util.HexDecodeString(tx.Data); err != nil {
 			return nil, errors.New("invalid data string")
@@ -225,7 +252,7 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 		// is legacy tx
 		return types.NewTx(&types.LegacyTx{
 			Nonce:    nonce,
-			To:       &toAddress,
+			To:       toAddress,
 			Value:    value,
 			Gas:      gasLimit,
 			GasPrice: gasPrice,
@@ -235,7 +262,7 @@ func (tx *Transaction) GetRawTx() (*types.Transaction, error) {
 		// is dynamic fee tx
 		return types.NewTx(&types.DynamicFeeTx{
 			Nonce:     nonce,
-			To:        &toAddress,
+			To:        toAddress,
 			Value:     value,
 			Gas:       gasLimit,
 			GasFeeCap: gasPrice,
@@ -0,0 +1,302 @@
+package abiutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"os"
+	"strings"
+)
+
+// Arg
+//
+//	@Description: one decoded argument
+type Arg struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// Call
+//
+//	@Description: the decoded calldata of a method
+type Call struct {
+	Name      string // the method name, eg: transfer
+	Signature string // eg: transfer(address,uint256)
+	Selector  string // hex of the first 4 bytes
+	Args      []Arg
+}
+
+// Event
+//
+//	@Description: the decoded log of an event
+type Event struct {
+	Name      string // the event name, eg: Transfer
+	Signature string // eg: Transfer(address,address,uint256)
+	Topic     string // hex of the event id, topics[0] of the log
+	Args      []Arg  // in the order of the abi, the indexed and the not indexed
+}
+
+// LoadFile
+//
+//	@Description: load the json abi file, the hardhat and foundry artifacts with an "abi" field are supported too
+//	@param path
+//	@return *abi.ABI
+//	@return error
+func LoadFile(path string) (*abi.ABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(string(data))
+}
+
+// Load
+//
+//	@Description: load the json abi string, the hardhat and foundry artifacts with an "abi" field are supported too
+//	@param abiJson
+//	@return *abi.ABI
+//	@return error
+func Load(abiJson string) (*abi.ABI, error) {
+	abiJson = strings.TrimSpace(abiJson)
+	if strings.HasPrefix(abiJson, "{") {
+		var artifact struct {
+			Abi json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal([]byte(abiJson), &artifact); err != nil {
+			return nil, err
+		}
+		if len(artifact.Abi) == 0 {
+			return nil, errors.New("the abi is not found")
+		}
+		abiJson = string(artifact.Abi)
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// Encode
+//
+//	@Description: the calldata of the method with go values, eg: common.Address, *big.Int
+//	@param contractAbi
+//	@param method
+//	@param args
+//	@return []byte
+//	@return error
+func Encode(contractAbi *abi.ABI, method string, args ...interface{}) ([]byte, error) {
+	if contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	return contractAbi.Pack(method, args...)
+}
+
+// EncodeJSON
+//
+//	@Description: the calldata of the method with json arguments,
+//	eg: ["0x8B63293748e058F47a31c0D2Af0B1b3FeDdc4D4C", "1000"], the numbers can be json numbers or strings in decimal or 0x hex,
+//	the bytes are 0x hex, the tuples are json arrays or objects by the field names
+//	@param contractAbi
+//	@param method
+//	@param jsonArgs a json array
+//	@return []byte
+//	@return error
+func EncodeJSON(contractAbi *abi.ABI, method, jsonArgs string) ([]byte, error) {
+	if contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	m, ok := contractAbi.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method '%s' not found", method)
+	}
+	var values []interface{}
+	if strings.TrimSpace(jsonArgs) != "" {
+		decoder := json.NewDecoder(strings.NewReader(jsonArgs))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+	}
+	args, err := ConvertArgs(m.Inputs, values)
+	if err != nil {
+		return nil, err
+	}
+	return contractAbi.Pack(method, args...)
+}
+
+// DecodeCalldata
+//
+//	@Description: the method and the named arguments of the calldata
+//	@param contractAbi
+//	@param data
+//	@return *Call
+//	@return error
+func DecodeCalldata(contractAbi *abi.ABI, data []byte) (*Call, error) {
+	if contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	if len(data) < 4 {
+		return nil, errors.New("calldata is too short")
+	}
+	method, err := contractAbi.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	call := &Call{
+		Name:      method.RawName,
+		Signature: method.Sig,
+		Selector:  hexutil.Encode(method.ID),
+		Args:      make([]Arg, len(values)),
+	}
+	for i, value := range values {
+		call.Args[i] = Arg{
+			Name:  method.Inputs[i].Name,
+			Type:  method.Inputs[i].Type.String(),
+			Value: value,
+		}
+	}
+	return call, nil
+}
+
+// DecodeCalldataHex
+//
+//	@Description: DecodeCalldata of the 0x hex calldata, eg: the input of a tx
+//	@param contractAbi
+//	@param data
+//	@return *Call
+//	@return error
+func DecodeCalldataHex(contractAbi *abi.ABI, data string) (*Call, error) {
+	payload, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCalldata(contractAbi, payload)
+}
+
+// DecodeReturn
+//
+//	@Description: the return values of the method in order
+//	@param contractAbi
+//	@param method
+//	@param data
+//	@return []interface{}
+//	@return error
+func DecodeReturn(contractAbi *abi.ABI, method string, data []byte) ([]interface{}, error) {
+	if contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	return contractAbi.Unpack(method, data)
+}
+
+// DecodeReturnMap
+//
+//	@Description: the return values of the method by the output names, the unnamed outputs are keyed by their index
+//	@param contractAbi
+//	@param method
+//	@param data
+//	@return map[string]interface{}
+//	@return error
+func DecodeReturnMap(contractAbi *abi.ABI, method string, data []byte) (map[string]interface{}, error) {
+	values, err := DecodeReturn(contractAbi, method, data)
+	if err != nil {
+		return nil, err
+	}
+	outputs := contractAbi.Methods[method].Outputs
+	results := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		name := outputs[i].Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		results[name] = value
+	}
+	return results, nil
+}
+
+// DecodeLog
+//
+//	@Description: the event and the named arguments of the log, the indexed arguments are decoded from the topics,
+//	the indexed string, bytes, array and tuple are keccak256 hashed by the evm, their values are the common.Hash
+//	@param contractAbi
+//	@param topics topics[0] is the event id, the anonymous events can't be decoded
+//	@param data
+//	@return *Event
+//	@return error
+func DecodeLog(contractAbi *abi.ABI, topics []common.Hash, data []byte) (*Event, error) {
+	if contractAbi == nil {
+		return nil, errors.New("abi is empty")
+	}
+	if len(topics) == 0 {
+		return nil, errors.New("the log has no topics")
+	}
+	event, err := contractAbi.EventByID(topics[0])
+	if err != nil {
+		return nil, err
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Event{
+		Name:      event.RawName,
+		Signature: event.Sig,
+		Topic:     event.ID.Hex(),
+		Args:      make([]Arg, 0, len(event.Inputs)),
+	}
+	topicIndex, valueIndex := 1, 0
+	for _, input := range event.Inputs {
+		var value interface{}
+		if input.Indexed {
+			if topicIndex >= len(topics) {
+				return nil, fmt.Errorf("the log has too few topics for the event %s", event.Sig)
+			}
+			value, err = decodeTopic(input.Type, topics[topicIndex])
+			if err != nil {
+				return nil, err
+			}
+			topicIndex++
+		} else {
+			value = values[valueIndex]
+			valueIndex++
+		}
+		result.Args = append(result.Args, Arg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: value,
+		})
+	}
+	if topicIndex != len(topics) {
+		// eg: erc721 Transfer has the same id as erc20 Transfer, but the tokenId is indexed
+		return nil, fmt.Errorf("the log has too many topics for the event %s", event.Sig)
+	}
+	return result, nil
+}
+
+// decodeTopic
+//
+//	@Description: the topic of a value type is its abi encoding, the others are hashed
+//	@param t
+//	@param topic
+//	@return interface{}
+//	@return error
+func decodeTopic(t abi.Type, topic common.Hash) (interface{}, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy, abi.BoolTy, abi.AddressTy, abi.FixedBytesTy:
+		values, err := abi.Arguments{{Type: t}}.Unpack(topic.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		return values[0], nil
+	}
+	return topic, nil
+}
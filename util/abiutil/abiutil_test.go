@@ -0,0 +1,136 @@
+package abiutil
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+const testAbi = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable",
+	 "inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],
+	 "outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"submit","stateMutability":"nonpayable",
+	 "inputs":[{"name":"orders","type":"tuple[]","components":[{"name":"maker","type":"address"},{"name":"amount","type":"uint256"},{"name":"flag","type":"uint8"}]},{"name":"salt","type":"bytes32"}],
+	 "outputs":[{"name":"count","type":"uint256"},{"name":"ok","type":"bool"}]}
+]`
+
+const testTo = "0x8B63293748e058F47a31c0D2Af0B1b3FeDdc4D4C"
+
+func TestEncodeAndDecode(t *testing.T) {
+	parsed, err := Load(testAbi)
+	require.Nil(t, err)
+
+	data, err := Encode(parsed, "transfer", common.HexToAddress(testTo), big.NewInt(1000))
+	require.Nil(t, err)
+	jsonData, err := EncodeJSON(parsed, "transfer", `["`+testTo+`", 1000]`)
+	require.Nil(t, err)
+	require.Equal(t, data, jsonData)
+	require.Equal(t, "0xa9059cbb", hexutil.Encode(data[:4]))
+
+	call, err := DecodeCalldataHex(parsed, hexutil.Encode(data))
+	require.Nil(t, err)
+	require.Equal(t, "transfer", call.Name)
+	require.Equal(t, "transfer(address,uint256)", call.Signature)
+	require.Equal(t, "to", call.Args[0].Name)
+	require.Equal(t, common.HexToAddress(testTo), call.Args[0].Value)
+	require.Equal(t, "1000", call.Args[1].Value.(*big.Int).String())
+
+	_, err = EncodeJSON(parsed, "transfer", `["`+testTo+`", -1]`)
+	require.NotNil(t, err)
+	_, err = EncodeJSON(parsed, "transfer", `["`+testTo+`"]`)
+	require.NotNil(t, err)
+}
+
+func TestEncodeJSONTuple(t *testing.T) {
+	parsed, err := Load(testAbi)
+	require.Nil(t, err)
+
+	salt := "0x" + "11" + "00000000000000000000000000000000000000000000000000000000000000"
+	data, err := EncodeJSON(parsed, "submit", `[[["`+testTo+`", "0x10", 1], {"maker": "`+testTo+`", "amount": "20", "flag": 2}], "`+salt+`"]`)
+	require.Nil(t, err)
+
+	call, err := DecodeCalldata(parsed, data)
+	require.Nil(t, err)
+	require.Equal(t, "submit", call.Name)
+	require.Len(t, call.Args, 2)
+
+	_, err = EncodeJSON(parsed, "submit", `[[["`+testTo+`", "1", 256]], "`+salt+`"]`)
+	require.NotNil(t, err)
+}
+
+func TestDecodeReturn(t *testing.T) {
+	parsed, err := Load(testAbi)
+	require.Nil(t, err)
+
+	data, err := parsed.Methods["submit"].Outputs.Pack(big.NewInt(3), true)
+	require.Nil(t, err)
+	values, err := DecodeReturnMap(parsed, "submit", data)
+	require.Nil(t, err)
+	require.Equal(t, "3", values["count"].(*big.Int).String())
+	require.Equal(t, true, values["ok"])
+}
+
+func TestLoadSignatures(t *testing.T) {
+	parsed, err := LoadSignatures(
+		"transfer(address,uint)",
+		"function balanceOf(address owner) view returns (uint256)",
+		"function submit((address maker, uint256 amount)[] calldata orders) returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	)
+	require.Nil(t, err)
+	require.Equal(t, "transfer(address,uint256)", parsed.Methods["transfer"].Sig)
+	require.True(t, parsed.Methods["balanceOf"].IsConstant())
+	require.Equal(t, "submit((address,uint256)[])", parsed.Methods["submit"].Sig)
+	require.True(t, parsed.Events["Transfer"].Inputs[0].Indexed)
+	require.Equal(t, "InsufficientBalance(uint256,uint256)", parsed.Errors["InsufficientBalance"].Sig)
+
+	// the same calldata as the json abi
+	data, err := EncodeJSON(parsed, "transfer", `["`+testTo+`", "1000"]`)
+	require.Nil(t, err)
+	require.Equal(t, "0xa9059cbb", hexutil.Encode(data[:4]))
+
+	_, err = LoadSignatures("transfer(address")
+	require.NotNil(t, err)
+	_, err = LoadSignatures("transfer(address) unknown")
+	require.NotNil(t, err)
+}
+
+func TestDecodeLog(t *testing.T) {
+	parsed, err := LoadSignatures(
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Named(string indexed name, uint256 id)",
+	)
+	require.Nil(t, err)
+	transfer := parsed.Events["Transfer"]
+	value, err := transfer.Inputs.NonIndexed().Pack(big.NewInt(1000))
+	require.Nil(t, err)
+	from := common.HexToAddress("0x7a547A149A79A03F4dd441B6806ffCBb1b63F383")
+	topics := []common.Hash{transfer.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(common.HexToAddress(testTo).Bytes())}
+
+	event, err := DecodeLog(parsed, topics, value)
+	require.Nil(t, err)
+	require.Equal(t, "Transfer", event.Name)
+	require.Equal(t, "Transfer(address,address,uint256)", event.Signature)
+	require.Equal(t, from, event.Args[0].Value)
+	require.Equal(t, common.HexToAddress(testTo), event.Args[1].Value)
+	require.Equal(t, "value", event.Args[2].Name)
+	require.Equal(t, "1000", event.Args[2].Value.(*big.Int).String())
+
+	// erc721 Transfer, the same id with one more topic
+	_, err = DecodeLog(parsed, append(topics, common.BigToHash(big.NewInt(1))), nil)
+	require.NotNil(t, err)
+
+	// the indexed string is hashed
+	named := parsed.Events["Named"]
+	id, err := named.Inputs.NonIndexed().Pack(big.NewInt(7))
+	require.Nil(t, err)
+	nameHash := common.BytesToHash(hexutil.MustDecode("0x1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8"))
+	event, err = DecodeLog(parsed, []common.Hash{named.ID, nameHash}, id)
+	require.Nil(t, err)
+	require.Equal(t, nameHash, event.Args[0].Value)
+	require.Equal(t, "7", event.Args[1].Value.(*big.Int).String())
+}
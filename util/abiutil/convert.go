@@ -0,0 +1,194 @@
+package abiutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// ConvertArgs
+//
+//	@Description: convert the json decoded values to the go values which abi.Pack accepts
+//	@param arguments the inputs of a method
+//	@param values
+//	@return []interface{}
+//	@return error
+func ConvertArgs(arguments abi.Arguments, values []interface{}) ([]interface{}, error) {
+	if len(values) != len(arguments) {
+		return nil, fmt.Errorf("argument count mismatch: got %d for %d", len(values), len(arguments))
+	}
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		arg, err := convert(arguments[i].Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+func convert(t abi.Type, value interface{}) (interface{}, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return convertInt(t, value)
+	case abi.BoolTy:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if v == "true" || v == "false" {
+				return v == "true", nil
+			}
+		}
+		return nil, fmt.Errorf("invalid bool: %v", value)
+	case abi.StringTy:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("invalid string: %v", value)
+	case abi.AddressTy:
+		if v, ok := value.(string); ok && common.IsHexAddress(v) {
+			return common.HexToAddress(v), nil
+		}
+		return nil, fmt.Errorf("invalid address: %v", value)
+	case abi.BytesTy:
+		return convertBytes(value)
+	case abi.FixedBytesTy:
+		data, err := convertBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != t.Size {
+			return nil, fmt.Errorf("invalid bytes%d: %v", t.Size, value)
+		}
+		array := reflect.New(t.GetType()).Elem()
+		reflect.Copy(array, reflect.ValueOf(data))
+		return array.Interface(), nil
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: %v", t.String(), value)
+		}
+		var list reflect.Value
+		if t.T == abi.SliceTy {
+			list = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return nil, fmt.Errorf("invalid %s: the length is %d", t.String(), len(items))
+			}
+			list = reflect.New(t.GetType()).Elem()
+		}
+		for i, item := range items {
+			elem, err := convert(*t.Elem, item)
+			if err != nil {
+				return nil, err
+			}
+			list.Index(i).Set(reflect.ValueOf(elem))
+		}
+		return list.Interface(), nil
+	case abi.TupleTy:
+		return convertTuple(t, value)
+	}
+	return nil, fmt.Errorf("unsupported type: %s", t.String())
+}
+
+// convertInt
+//
+//	@Description: *big.Int for the sizes bigger than 64, otherwise the go int type of the size, eg: uint8
+//	@param t
+//	@param value
+//	@return interface{}
+//	@return error
+func convertInt(t abi.Type, value interface{}) (interface{}, error) {
+	var number string
+	switch v := value.(type) {
+	case json.Number:
+		number = v.String()
+	case string:
+		number = v
+	default:
+		return nil, fmt.Errorf("invalid %s: %v", t.String(), value)
+	}
+	i, ok := new(big.Int), false
+	if strings.HasPrefix(number, "0x") || strings.HasPrefix(number, "0X") {
+		i, ok = i.SetString(number[2:], 16)
+	} else {
+		i, ok = i.SetString(number, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: %v", t.String(), value)
+	}
+	bits := t.Size
+	if t.T == abi.IntTy {
+		bits-- // the sign bit
+	}
+	if (t.T == abi.UintTy && i.Sign() < 0) || i.BitLen() > bits {
+		return nil, fmt.Errorf("%s overflow: %v", t.String(), value)
+	}
+	if t.Size > 64 {
+		return i, nil
+	}
+	result := reflect.New(t.GetType()).Elem()
+	if t.T == abi.UintTy {
+		result.SetUint(i.Uint64())
+	} else {
+		result.SetInt(i.Int64())
+	}
+	return result.Interface(), nil
+}
+
+func convertBytes(value interface{}) ([]byte, error) {
+	v, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytes: %v", value)
+	}
+	if v == "" || v == "0x" {
+		return []byte{}, nil
+	}
+	return hexutil.Decode(v)
+}
+
+// convertTuple
+//
+//	@Description: the tuple is a json array in the order of the fields, or a json object by the field names
+//	@param t
+//	@param value
+//	@return interface{}
+//	@return error
+func convertTuple(t abi.Type, value interface{}) (interface{}, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		items = make([]interface{}, len(t.TupleRawNames))
+		for i, name := range t.TupleRawNames {
+			item, ok := v[name]
+			if !ok {
+				return nil, fmt.Errorf("the field '%s' of the tuple is missing", name)
+			}
+			items[i] = item
+		}
+	default:
+		return nil, fmt.Errorf("invalid tuple: %v", value)
+	}
+	if len(items) != len(t.TupleElems) {
+		return nil, errors.New("the field count of the tuple is wrong")
+	}
+	tuple := reflect.New(t.GetType()).Elem()
+	for i, item := range items {
+		field, err := convert(*t.TupleElems[i], item)
+		if err != nil {
+			return nil, err
+		}
+		tuple.Field(i).Set(reflect.ValueOf(field))
+	}
+	return tuple.Interface(), nil
+}
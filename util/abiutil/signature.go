@@ -0,0 +1,242 @@
+package abiutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"strings"
+)
+
+// abiEntry the json abi of a function, an event or an error
+type abiEntry struct {
+	Type            string          `json:"type"`
+	Name            string          `json:"name"`
+	Inputs          []abiEntryParam `json:"inputs"`
+	Outputs         []abiEntryParam `json:"outputs,omitempty"`
+	StateMutability string          `json:"stateMutability,omitempty"`
+	Anonymous       bool            `json:"anonymous,omitempty"`
+}
+
+type abiEntryParam struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Indexed    bool            `json:"indexed,omitempty"`
+	Components []abiEntryParam `json:"components,omitempty"`
+}
+
+// LoadSignatures
+//
+//	@Description: load the human readable signatures, eg:
+//	"transfer(address,uint256)",
+//	"balanceOf(address)(uint256)",
+//	"function transfer(address to, uint256 amount) returns (bool)",
+//	"function balanceOf(address owner) view returns (uint256)",
+//	"event Transfer(address indexed from, address indexed to, uint256 value)",
+//	"error InsufficientBalance(uint256 available, uint256 required)",
+//	the tuples are in parentheses, eg: "submit((address,uint256)[] orders)"
+//	@param signatures
+//	@return *abi.ABI
+//	@return error
+func LoadSignatures(signatures ...string) (*abi.ABI, error) {
+	entries := make([]abiEntry, 0, len(signatures))
+	for _, signature := range signatures {
+		entry, err := parseSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature '%s': %w", signature, err)
+		}
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return Load(string(data))
+}
+
+func parseSignature(signature string) (abiEntry, error) {
+	entry := abiEntry{Type: "function", StateMutability: "nonpayable"}
+	rest := strings.TrimSpace(signature)
+	for _, kind := range []string{"function", "event", "error"} {
+		if strings.HasPrefix(rest, kind+" ") {
+			entry.Type = kind
+			rest = strings.TrimSpace(rest[len(kind):])
+			break
+		}
+	}
+	if entry.Type != "function" {
+		entry.StateMutability = ""
+	}
+
+	open := strings.Index(rest, "(")
+	if open <= 0 {
+		return entry, errors.New("the name or the parameters are missing")
+	}
+	entry.Name = strings.TrimSpace(rest[:open])
+	params, rest, err := splitGroup(rest[open:])
+	if err != nil {
+		return entry, err
+	}
+	if entry.Inputs, err = parseParams(params, entry.Type == "event"); err != nil {
+		return entry, err
+	}
+
+	// the modifiers and the outputs
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		if strings.HasPrefix(rest, "(") {
+			if entry.Type != "function" {
+				return entry, errors.New("only a function has outputs")
+			}
+			if params, rest, err = splitGroup(rest); err != nil {
+				return entry, err
+			}
+			if entry.Outputs, err = parseParams(params, false); err != nil {
+				return entry, err
+			}
+			continue
+		}
+		word := rest
+		if i := strings.IndexAny(rest, " ("); i >= 0 {
+			word = rest[:i]
+		}
+		rest = rest[len(word):]
+		switch word {
+		case "view", "pure", "payable", "nonpayable":
+			entry.StateMutability = word
+		case "anonymous":
+			entry.Anonymous = true
+		case "returns", "external", "public":
+		default:
+			return entry, fmt.Errorf("unknown modifier '%s'", word)
+		}
+	}
+	return entry, nil
+}
+
+// splitGroup
+//
+//	@Description: the content of the parentheses at the beginning of s, and the rest after them
+//	@param s
+//	@return group
+//	@return rest
+//	@return err
+func splitGroup(s string) (group, rest string, err error) {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", errors.New("the parentheses are not closed")
+}
+
+// splitParams
+//
+//	@Description: split by the commas which are not in parentheses
+//	@param s
+//	@return []string
+func splitParams(s string) []string {
+	var params []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				params = append(params, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(params, s[start:])
+}
+
+func parseParams(s string, event bool) ([]abiEntryParam, error) {
+	params := make([]abiEntryParam, 0)
+	if strings.TrimSpace(s) == "" {
+		return params, nil
+	}
+	for _, item := range splitParams(s) {
+		param, err := parseParam(strings.TrimSpace(item), event)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// parseParam
+//
+//	@Description: eg: "address", "address indexed from", "(address,uint256)[] orders", "tuple(address,uint256)"
+//	@param s
+//	@param event
+//	@return abiEntryParam
+//	@return error
+func parseParam(s string, event bool) (abiEntryParam, error) {
+	var param abiEntryParam
+	if s == "" {
+		return param, errors.New("the parameter is empty")
+	}
+	if strings.HasPrefix(s, "tuple(") {
+		s = strings.TrimPrefix(s, "tuple")
+	}
+
+	var rest string
+	if strings.HasPrefix(s, "(") {
+		group, after, err := splitGroup(s)
+		if err != nil {
+			return param, err
+		}
+		if param.Components, err = parseParams(group, false); err != nil {
+			return param, err
+		}
+		suffix := after
+		if i := strings.Index(after, " "); i >= 0 {
+			suffix, rest = after[:i], after[i:]
+		}
+		param.Type = "tuple" + suffix
+	} else {
+		fields := strings.SplitN(s, " ", 2)
+		param.Type = normalizeType(fields[0])
+		if len(fields) > 1 {
+			rest = fields[1]
+		}
+	}
+
+	for _, word := range strings.Fields(rest) {
+		switch {
+		case word == "indexed" && event:
+			param.Indexed = true
+		case word == "memory" || word == "calldata" || word == "storage":
+		case param.Name == "":
+			param.Name = word
+		default:
+			return param, fmt.Errorf("invalid parameter '%s'", s)
+		}
+	}
+	return param, nil
+}
+
+// normalizeType
+//
+//	@Description: uint and int are the aliases of uint256 and int256
+//	@param t
+//	@return string
+func normalizeType(t string) string {
+	for _, alias := range []string{"uint", "int"} {
+		if t == alias || strings.HasPrefix(t, alias+"[") {
+			return alias + "256" + t[len(alias):]
+		}
+	}
+	return t
+}
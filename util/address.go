@@ -1,5 +1,11 @@
 package util
 
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
 // Lengths of hashes and addresses in bytes.
 const (
 	// HashLength is the expected length of the hash
@@ -23,3 +29,56 @@ func IsHexAddress(s string) bool {
 func has0xPrefix(str string) bool {
 	return len(str) >= 2 && str[0] == '0' && (str[1] == 'x' || str[1] == 'X')
 }
+
+// CreateAddress
+//
+//	@Description: the address of the contract created by the sender with the nonce
+//	@param sender
+//	@param nonce the nonce of the deployment tx
+//	@return string
+//	@return error
+func CreateAddress(sender string, nonce uint64) (string, error) {
+	if !IsValidAddress(sender) {
+		return "", errors.New("address format is error")
+	}
+	return crypto.CreateAddress(common.HexToAddress(sender), nonce).Hex(), nil
+}
+
+// Create2Address
+//
+//	@Description: the address of the contract created by CREATE2 of the deployer, see eip1014
+//	@param deployer the factory contract which runs CREATE2
+//	@param salt hex, at most 32 bytes, left padded with 0
+//	@param initCode hex, the creation bytecode with the abi encoded constructor args
+//	@return string
+//	@return error
+func Create2Address(deployer, salt, initCode string) (string, error) {
+	code, err := HexDecodeString(initCode)
+	if err != nil {
+		return "", err
+	}
+	return Create2AddressFromHash(deployer, salt, HexEncodeToString(crypto.Keccak256(code)))
+}
+
+// Create2AddressFromHash
+//
+//	@Description: the same as Create2Address, but the keccak256 hash of the init code is given
+//	@param deployer
+//	@param salt
+//	@param initCodeHash hex, 32 bytes
+//	@return string
+//	@return error
+func Create2AddressFromHash(deployer, salt, initCodeHash string) (string, error) {
+	if !IsValidAddress(deployer) {
+		return "", errors.New("address format is error")
+	}
+	saltBytes, err := HexDecodeString(salt)
+	if err != nil || len(saltBytes) > HashLength {
+		return "", errors.New("salt must be hex of at most 32 bytes")
+	}
+	hashBytes, err := HexDecodeString(initCodeHash)
+	if err != nil || len(hashBytes) != HashLength {
+		return "", errors.New("init code hash must be hex of 32 bytes")
+	}
+	return crypto.CreateAddress2(common.HexToAddress(deployer), common.BytesToHash(saltBytes), hashBytes).Hex(), nil
+}
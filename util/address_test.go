@@ -0,0 +1,45 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestCreateAddress(t *testing.T) {
+	address, err := CreateAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 0)
+	require.Nil(t, err)
+	require.Equal(t, "0xcd234A471b72ba2F1Ccf0A70FCABA648a5eeCD8d", address)
+	address, err = CreateAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", 1)
+	require.Nil(t, err)
+	require.Equal(t, "0x343c43A37D37dfF08AE8C4A11544c718AbB4fCF8", address)
+
+	_, err = CreateAddress("0x123", 0)
+	require.NotNil(t, err)
+}
+
+func TestCreate2Address(t *testing.T) {
+	// the examples of eip1014
+	cases := []struct {
+		deployer string
+		salt     string
+		initCode string
+		address  string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00", "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0xdeadbeef", "0x70f2b2914A2a4b783FaEFb75f459A580616Fcb5e"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x", "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"},
+	}
+	for _, c := range cases {
+		address, err := Create2Address(c.deployer, c.salt, c.initCode)
+		require.Nil(t, err)
+		require.Equal(t, c.address, address)
+	}
+
+	_, err := Create2Address("0x0000000000000000000000000000000000000000", "0x"+strings.Repeat("00", 33), "0x00")
+	require.NotNil(t, err)
+	_, err = Create2AddressFromHash("0x0000000000000000000000000000000000000000", "0x00", "0x1234")
+	require.NotNil(t, err)
+}
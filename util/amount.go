@@ -0,0 +1,42 @@
+package util
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+)
+
+// ParseUnits
+//
+//	@Description: convert a human amount to the min unit, eg: "1.5" with 18 decimals is "1500000000000000000"
+//	@param amount
+//	@param decimals
+//	@return *big.Int
+//	@return error the amount is negative, or has more decimal places than decimals
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsNegative() {
+		return nil, errors.New("amount can't be negative")
+	}
+	value = value.Shift(int32(decimals))
+	if !value.Equal(value.Truncate(0)) {
+		return nil, errors.New("amount has too many decimal places")
+	}
+	return value.BigInt(), nil
+}
+
+// FormatUnits
+//
+//	@Description: convert the min unit to a human amount, eg: "1500000000000000000" with 18 decimals is "1.5"
+//	@param value
+//	@param decimals
+//	@return decimal.Decimal
+func FormatUnits(value *big.Int, decimals uint8) decimal.Decimal {
+	if value == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(value, -int32(decimals))
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	value, err := ParseUnits("1.5", 18)
+	require.Nil(t, err)
+	require.Equal(t, "1500000000000000000", value.String())
+
+	value, err = ParseUnits("100", 0)
+	require.Nil(t, err)
+	require.Equal(t, "100", value.String())
+
+	_, err = ParseUnits("1.0000001", 6)
+	require.NotNil(t, err)
+	_, err = ParseUnits("-1", 6)
+	require.NotNil(t, err)
+	_, err = ParseUnits("abc", 6)
+	require.NotNil(t, err)
+}
+
+func TestFormatUnits(t *testing.T) {
+	value, _ := new(big.Int).SetString("1500000000000000000", 10)
+	require.Equal(t, "1.5", FormatUnits(value, 18).String())
+	require.Equal(t, "0.000001", FormatUnits(big.NewInt(1), 6).String())
+	require.Equal(t, "0", FormatUnits(nil, 6).String())
+}
@@ -1,8 +1,10 @@
 package signutil
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/bitxx/evm-utils/model/signer"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -25,6 +27,25 @@ func VerifyEip721Signature(address, signature string, typedData apitypes.TypedDa
 	return valid, err
 }
 
+// SignEip721WithSigner
+//
+//	@Description: eip712 sign with any signer, eg: keystore, clef
+//	@param ctx
+//	@param s
+//	@param typedData
+//	@return string
+//	@return error
+func SignEip721WithSigner(ctx context.Context, s signer.Signer, typedData *apitypes.TypedData) (string, error) {
+	if s == nil || typedData == nil {
+		return "", errors.New("invalid parameter")
+	}
+	signature, err := s.SignTypedData(ctx, typedData)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signature), nil
+}
+
 func SignEip721(privateKey string, typedData *apitypes.TypedData) (string, error) {
 	if privateKey == "" || typedData == nil {
 		return "", errors.New("invalid parameter")
@@ -58,6 +79,25 @@ func SignEip721(privateKey string, typedData *apitypes.TypedData) (string, error
 	return hexutil.Encode(signature), nil
 }
 
+// MetamaskSignLoginWithSigner
+//
+//	@Description: personal_sign with any signer, eg: keystore, clef
+//	@param ctx
+//	@param message
+//	@param s
+//	@return string
+//	@return error
+func MetamaskSignLoginWithSigner(ctx context.Context, message string, s signer.Signer) (string, error) {
+	if s == nil {
+		return "", errors.New("invalid parameter")
+	}
+	signature, err := s.SignText(ctx, []byte(message))
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signature), nil
+}
+
 func MetamaskSignLogin(message string, privateKey string) (string, error) {
 	ecdsaPrivateKey, err := crypto.HexToECDSA(privateKey)
 	if err != nil {